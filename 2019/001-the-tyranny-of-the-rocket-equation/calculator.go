@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Formula returns the fuel needed to lift a mass. Negative results mean no
+// fuel is needed.
+type Formula func(mass int) int
+
+// Linear divides the mass and subtracts the offset, the puzzle uses
+// Linear(3, 2).
+func Linear(divisor, offset int) Formula {
+	return func(mass int) int {
+		return mass/divisor - offset
+	}
+}
+
+type ModuleFuel struct {
+	Mass        int
+	Fuel        int // fuel for the module alone
+	FuelForFuel int // fuel for the fuel, and its fuel, ...
+	Depth       int // number of times fuel needed more fuel
+}
+
+func (m ModuleFuel) Total() int {
+	return m.Fuel + m.FuelForFuel
+}
+
+type Calculator struct {
+	Formula Formula
+}
+
+func NewCalculator(formula Formula) *Calculator {
+	return &Calculator{Formula: formula}
+}
+
+// Module computes the fuel for a module. Fuel itself requires fuel just like
+// a module, any mass that would require negative fuel is treated as if it
+// requires zero fuel.
+//
+// The chain is followed in a loop, so huge masses or formulas that shrink
+// slowly don't run out of stack. A formula that doesn't shrink the mass
+// would never end and is reported instead.
+func (c *Calculator) Module(mass int) (ModuleFuel, error) {
+	result := ModuleFuel{Mass: mass}
+
+	fuel := c.Formula(mass)
+	if fuel <= 0 {
+		return result, nil
+	}
+
+	result.Fuel = fuel
+
+	for {
+		next := c.Formula(fuel)
+		if next <= 0 {
+			return result, nil
+		}
+
+		if next >= fuel {
+			return result, fmt.Errorf("Formula does not converge for mass %d: %d fuel needs %d fuel", mass, fuel, next)
+		}
+
+		result.FuelForFuel += next
+		result.Depth++
+		fuel = next
+	}
+}
+
+func (c *Calculator) Breakdown(masses []int) ([]ModuleFuel, error) {
+	result := []ModuleFuel{}
+
+	for _, m := range masses {
+		module, err := c.Module(m)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, module)
+	}
+
+	return result, nil
+}
+
+// Totals sums the fuel for the modules alone and with fuel for the fuel.
+func Totals(modules []ModuleFuel) (int, int) {
+	fuel, total := 0, 0
+
+	for _, m := range modules {
+		fuel += m.Fuel
+		total += m.Total()
+	}
+
+	return fuel, total
+}
+
+func WriteTable(w io.Writer, modules []ModuleFuel) error {
+	t := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(t, "module\tmass\tfuel\tfuel for fuel\tdepth\ttotal\t")
+
+	for i, m := range modules {
+		fmt.Fprintf(t, "%d\t%d\t%d\t%d\t%d\t%d\t\n", i+1, m.Mass, m.Fuel, m.FuelForFuel, m.Depth, m.Total())
+	}
+
+	fuel, total := Totals(modules)
+
+	fmt.Fprintf(t, "all\t\t%d\t%d\t\t%d\t\n", fuel, total-fuel, total)
+
+	return t.Flush()
+}
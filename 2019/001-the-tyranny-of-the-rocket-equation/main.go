@@ -2,40 +2,39 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
-func check(e error) {
-	if e != nil {
-		panic(e)
-	}
-}
-
-func loadModuleMasses(filename string) []int {
+// loadModuleMasses reads one positive mass per line.
+func loadModuleMasses(filename string) ([]int, error) {
 	result := []int{}
 
-	inputFile, err := os.OpenFile(filename, os.O_RDONLY, os.ModePerm)
-	check(err)
+	inputFile, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
 	defer inputFile.Close()
 
-	reader := bufio.NewReader(inputFile)
-
-	for {
-		line, err := reader.ReadString('\n')
+	scanner := bufio.NewScanner(inputFile)
 
-		if err != nil {
-			break // EOF
+	for line := 1; scanner.Scan(); line++ {
+		mass, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+		if err != nil || mass <= 0 {
+			return nil, fmt.Errorf("Line %d: invalid mass %q", line, scanner.Text())
 		}
 
-		mass, err := strconv.ParseInt(line[0:len(line)-1], 10, 64)
-		check(err)
+		result = append(result, mass)
+	}
 
-		result = append(result, int(mass))
+	if err := scanner.Err(); err != nil {
+		return nil, err
 	}
 
-	return result
+	return result, nil
 }
 
 //
@@ -45,37 +44,45 @@ func fuelForMass(mass int) int {
 	return mass/3 - 2
 }
 
-//
-// Fuel itself requires fuel just like a module - take its mass, divide by
-// three, round down, and subtract 2. However, that fuel also requires fuel,
-// and that fuel requires fuel, and so on. Any mass that would require negative
-// fuel should instead be treated as if it requires zero fuel; the remaining
-// mass, if any, is instead handled by wishing really hard, which has no mass
-// and is outside the scope of this calculation.
-//
-func fuelForFuel(fuel int) int {
-	result := fuelForMass(fuel)
+func main() {
+	input := flag.String("input", "input1.txt", "file with one module mass per line")
+	divisor := flag.Int("divisor", 3, "fuel formula: mass / divisor - offset")
+	offset := flag.Int("offset", 2, "fuel formula: mass / divisor - offset")
+	table := flag.Bool("table", false, "print the fuel for every module")
+
+	flag.Parse()
+
+	formula := Formula(fuelForMass)
+	if *divisor != 3 || *offset != 2 {
+		if *divisor <= 0 {
+			fmt.Println("Divisor has to be positive")
+			os.Exit(1)
+		}
 
-	if result <= 0 {
-		return 0
+		formula = Linear(*divisor, *offset)
 	}
 
-	return result + fuelForFuel(result)
-}
-
-func main() {
-	fuelForModules := 0
-	totalFuel := 0
-
-	moduleMasses := loadModuleMasses("input1.txt")
+	masses, err := loadModuleMasses(*input)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-	for _, m := range moduleMasses {
-		fuel := fuelForMass(m)
+	modules, err := NewCalculator(formula).Breakdown(masses)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
-		fuelForModules += fuel
-		totalFuel += fuel + fuelForFuel(fuel)
+	if *table {
+		if err := WriteTable(os.Stdout, modules); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
 	}
 
+	fuelForModules, totalFuel := Totals(modules)
+
 	fmt.Printf("Fuel for modules: %d\n", fuelForModules)
 	fmt.Printf("Total Fuel: %d\n", totalFuel)
 }
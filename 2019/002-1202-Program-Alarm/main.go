@@ -2,6 +2,9 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -134,47 +137,32 @@ func (p *Program) Run() error {
 	return p.Run()
 }
 
+// loadProgram reads a comma separated intcode program.
+func loadProgram(filename string) ([]int, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	code := []int{}
+
+	for _, field := range strings.Split(strings.TrimSpace(string(content)), ",") {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value %q in %s", field, filename)
+		}
+
+		code = append(code, v)
+	}
+
+	return code, nil
+}
+
 func main() {
-	programCode := []int{
-		1, 0, 0, 3,
-		1, 1, 2, 3,
-		1, 3, 4, 3,
-		1, 5, 0, 3,
-		2, 9, 1, 19,
-		1, 19, 5, 23,
-		1, 9, 23, 27,
-		2, 27, 6, 31,
-		1, 5, 31, 35,
-		2, 9, 35, 39,
-		2, 6, 39, 43,
-		2, 43, 13, 47,
-		2, 13, 47, 51,
-		1, 10, 51, 55,
-		1, 9, 55, 59,
-		1, 6, 59, 63,
-		2, 63, 9, 67,
-		1, 67, 6, 71,
-		1, 71, 13, 75,
-		1, 6, 75, 79,
-		1, 9, 79, 83,
-		2, 9, 83, 87,
-		1, 87, 6, 91,
-		1, 91, 13, 95,
-		2, 6, 95, 99,
-		1, 10, 99, 103,
-		2, 103, 9, 107,
-		1, 6, 107, 111,
-		1, 10, 111, 115,
-		2, 6, 115, 119,
-		1, 5, 119, 123,
-		1, 123, 13, 127,
-		1, 127, 5, 131,
-		1, 6, 131, 135,
-		2, 135, 13, 139,
-		1, 139, 2, 143,
-		1, 143, 10, 0,
-		99,
-		2, 0, 14, 0,
+	programCode, err := loadProgram("input.txt")
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
 	for noun := 0; noun < 100; noun++ {
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Segment is one straight move of a wire. Steps is how far the wire ran
+// before reaching From.
+type Segment struct {
+	Wire  int
+	From  Point
+	To    Point
+	Steps int
+}
+
+func (s Segment) Horizontal() bool {
+	return s.From.Y == s.To.Y
+}
+
+// bounds returns the smaller and the larger end on the moving axis.
+func (s Segment) bounds() (int, int) {
+	a, b := s.From.Y, s.To.Y
+	if s.Horizontal() {
+		a, b = s.From.X, s.To.X
+	}
+
+	if a > b {
+		a, b = b, a
+	}
+
+	return a, b
+}
+
+// StepsTo is the length of the wire up to a point on the segment.
+func (s Segment) StepsTo(p Point) int {
+	return s.Steps + abs(p.X-s.From.X) + abs(p.Y-s.From.Y)
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
+
+// ParsePath reads a path like "R8,U5,L5,D3" starting at the origin.
+func ParsePath(wire int, path string) ([]Segment, error) {
+	result := []Segment{}
+	pos := Point{}
+	steps := 0
+
+	for i, move := range strings.Split(strings.TrimSpace(path), ",") {
+		move = strings.TrimSpace(move)
+
+		if len(move) < 2 {
+			return nil, fmt.Errorf("Move %d: invalid move %q", i+1, move)
+		}
+
+		length, err := strconv.Atoi(move[1:])
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf("Move %d: invalid length in %q", i+1, move)
+		}
+
+		next := pos
+
+		switch move[0] {
+		case 'U':
+			next.Y += length
+		case 'D':
+			next.Y -= length
+		case 'L':
+			next.X -= length
+		case 'R':
+			next.X += length
+		default:
+			return nil, fmt.Errorf("Move %d: unknown direction in %q", i+1, move)
+		}
+
+		result = append(result, Segment{Wire: wire, From: pos, To: next, Steps: steps})
+
+		steps += length
+		pos = next
+	}
+
+	return result, nil
+}
+
+// Crossing is a point where two different wires meet, other than the
+// origin. Steps is the combined length of both wires up to the point, each
+// counted the first time it gets there.
+type Crossing struct {
+	Point
+	A, B  int
+	Steps int
+}
+
+type crossingKey struct {
+	p    Point
+	a, b int
+}
+
+type crossings map[crossingKey]Crossing
+
+func (c crossings) add(p Point, s1, s2 Segment) {
+	if p == (Point{}) || s1.Wire == s2.Wire {
+		return
+	}
+
+	if s1.Wire > s2.Wire {
+		s1, s2 = s2, s1
+	}
+
+	key := crossingKey{p: p, a: s1.Wire, b: s2.Wire}
+	steps := s1.StepsTo(p) + s2.StepsTo(p)
+
+	if old, ok := c[key]; ok && old.Steps <= steps {
+		return
+	}
+
+	c[key] = Crossing{Point: p, A: s1.Wire, B: s2.Wire, Steps: steps}
+}
+
+// Intersect finds every crossing between the wires. Horizontal and
+// vertical segments are matched with a sweep line over x, parallel
+// segments that overlap are compared on their shared line.
+func Intersect(wires [][]Segment) []Crossing {
+	found := crossings{}
+
+	horizontal := []Segment{}
+	vertical := []Segment{}
+
+	for _, w := range wires {
+		for _, s := range w {
+			if s.Horizontal() {
+				horizontal = append(horizontal, s)
+			} else {
+				vertical = append(vertical, s)
+			}
+		}
+	}
+
+	sweep(horizontal, vertical, found)
+	overlaps(horizontal, found)
+	overlaps(vertical, found)
+
+	result := []Crossing{}
+	for _, c := range found {
+		result = append(result, c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].X != result[j].X {
+			return result[i].X < result[j].X
+		}
+		if result[i].Y != result[j].Y {
+			return result[i].Y < result[j].Y
+		}
+		if result[i].A != result[j].A {
+			return result[i].A < result[j].A
+		}
+		return result[i].B < result[j].B
+	})
+
+	return result
+}
+
+const (
+	eventAdd = iota
+	eventQuery
+	eventRemove
+)
+
+type event struct {
+	x       int
+	kind    int
+	segment Segment
+}
+
+func sweep(horizontal, vertical []Segment, found crossings) {
+	events := []event{}
+
+	for _, h := range horizontal {
+		min, max := h.bounds()
+
+		events = append(events, event{x: min, kind: eventAdd, segment: h})
+		events = append(events, event{x: max, kind: eventRemove, segment: h})
+	}
+
+	for _, v := range vertical {
+		events = append(events, event{x: v.From.X, kind: eventQuery, segment: v})
+	}
+
+	// at the same x segments are added before and removed after the
+	// queries, so touching ends count
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].x != events[j].x {
+			return events[i].x < events[j].x
+		}
+
+		return events[i].kind < events[j].kind
+	})
+
+	// horizontal segments crossing the sweep line, sorted by y
+	active := []Segment{}
+
+	for _, e := range events {
+		y := e.segment.From.Y
+
+		switch e.kind {
+		case eventAdd:
+			i := sort.Search(len(active), func(i int) bool { return active[i].From.Y >= y })
+			active = append(active, Segment{})
+			copy(active[i+1:], active[i:])
+			active[i] = e.segment
+		case eventRemove:
+			for i := sort.Search(len(active), func(i int) bool { return active[i].From.Y >= y }); i < len(active); i++ {
+				if active[i] == e.segment {
+					active = append(active[:i], active[i+1:]...)
+					break
+				}
+			}
+		case eventQuery:
+			min, max := e.segment.bounds()
+
+			for i := sort.Search(len(active), func(i int) bool { return active[i].From.Y >= min }); i < len(active) && active[i].From.Y <= max; i++ {
+				found.add(Point{X: e.x, Y: active[i].From.Y}, active[i], e.segment)
+			}
+		}
+	}
+}
+
+// overlaps compares parallel segments on the same line, every shared
+// point is a crossing.
+func overlaps(segments []Segment, found crossings) {
+	lines := map[int][]Segment{}
+
+	for _, s := range segments {
+		line := s.From.X
+		if s.Horizontal() {
+			line = s.From.Y
+		}
+
+		lines[line] = append(lines[line], s)
+	}
+
+	for line, group := range lines {
+		sort.Slice(group, func(i, j int) bool {
+			a, _ := group[i].bounds()
+			b, _ := group[j].bounds()
+			return a < b
+		})
+
+		for i := range group {
+			_, maxI := group[i].bounds()
+
+			for j := i + 1; j < len(group); j++ {
+				minJ, maxJ := group[j].bounds()
+				if minJ > maxI {
+					break
+				}
+
+				end := maxI
+				if maxJ < end {
+					end = maxJ
+				}
+
+				for k := minJ; k <= end; k++ {
+					p := Point{X: line, Y: k}
+					if group[i].Horizontal() {
+						p = Point{X: k, Y: line}
+					}
+
+					found.add(p, group[i], group[j])
+				}
+			}
+		}
+	}
+}
+
+// Closest is the crossing nearest to the origin.
+func Closest(crossings []Crossing) (Crossing, error) {
+	if len(crossings) == 0 {
+		return Crossing{}, fmt.Errorf("Wires don't cross")
+	}
+
+	best := crossings[0]
+
+	for _, c := range crossings[1:] {
+		if c.Manhattan() < best.Manhattan() {
+			best = c
+		}
+	}
+
+	return best, nil
+}
+
+// Fewest is the crossing with the shortest combined wire length.
+func Fewest(crossings []Crossing) (Crossing, error) {
+	if len(crossings) == 0 {
+		return Crossing{}, fmt.Errorf("Wires don't cross")
+	}
+
+	best := crossings[0]
+
+	for _, c := range crossings[1:] {
+		if c.Steps < best.Steps {
+			best = c
+		}
+	}
+
+	return best, nil
+}
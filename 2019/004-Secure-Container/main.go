@@ -26,15 +26,106 @@ func meets_criteria(i int) bool {
 	return adj && inc
 }
 
-func main() {
-	count := 0
+func assert(name string, v bool) {
+	if v {
+		fmt.Println("OK", name)
+	} else {
+		fmt.Println("FAILURE", name)
+	}
+}
+
+func crossCheck(rules *Rules, low, high int) {
+	count, err := rules.Count(low, high)
+	brute := rules.BruteForce(low, high)
+
+	assert(fmt.Sprintf("%s in %d-%d: %d == %d", rules, low, high, count, brute), err == nil && count == brute)
+}
+
+func test1() {
+	for digits := 1; digits <= 5; digits++ {
+		limit := 1
+		for i := 0; i < digits; i++ {
+			limit *= 10
+		}
+
+		for _, rule := range []Rule{HasPair(), HasExactPair()} {
+			rules, _ := NewRules(digits, NonDecreasing(), rule)
+			crossCheck(rules, 0, limit-1)
 
-	for i := start; i < end; i++ {
+			rules, _ = NewRules(digits, rule)
+			crossCheck(rules, limit/7, limit/3)
+		}
+	}
+}
+
+func test2() {
+	rules, _ := NewRules(6, NonDecreasing(), HasExactPair())
+	crossCheck(rules, start, end)
+
+	count := 0
+	for i := start; i <= end; i++ {
 		if meets_criteria(i) {
-			fmt.Println(i)
 			count++
 		}
 	}
 
-	fmt.Println(count)
+	dp, _ := rules.Count(start, end)
+	assert(fmt.Sprintf("Rule engine agrees with meets_criteria: %d == %d", dp, count), dp == count)
+}
+
+func test3() {
+	evenSum := Predicate("even-digit-sum", func(digits []int) bool {
+		sum := 0
+		for _, d := range digits {
+			sum += d
+		}
+
+		return sum%2 == 0
+	})
+
+	rules, _ := NewRules(5, NonDecreasing(), HasPair(), evenSum)
+	crossCheck(rules, 0, 99999)
+
+	rules, _ = NewRules(4, evenSum)
+	crossCheck(rules, 1234, 8765)
+}
+
+func test4() {
+	rules, _ := NewRules(3, NonDecreasing())
+
+	_, err := rules.Count(0, 1000)
+	assert("Range larger than the digits is an error", err != nil)
+
+	count, err := rules.Count(500, 400)
+	assert("Empty range counts nothing", err == nil && count == 0)
+
+	_, err = NewRules(0)
+	assert("Zero digits is an error", err != nil)
+}
+
+func main() {
+	part1, _ := NewRules(6, NonDecreasing(), HasPair())
+	part2, _ := NewRules(6, NonDecreasing(), HasExactPair())
+
+	count1, err := part1.Count(start, end)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	count2, err := part2.Count(start, end)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(count1)
+	fmt.Println(count2)
+
+	fmt.Println("------------")
+
+	test1()
+	test2()
+	test3()
+	test4()
 }
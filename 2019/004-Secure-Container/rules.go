@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule checks a password one digit at a time, from the most significant.
+// States are plain ints so the counter can remember them.
+type Rule struct {
+	Name   string
+	Start  int
+	Next   func(state, digit int) (int, bool) // false rejects the password
+	Accept func(state int) bool
+}
+
+// NonDecreasing rejects a digit smaller than the one before it.
+func NonDecreasing() Rule {
+	// state is the last digit + 1, 0 before the first digit
+	return Rule{
+		Name:  "non-decreasing",
+		Start: 0,
+		Next: func(state, digit int) (int, bool) {
+			return digit + 1, digit+1 >= state
+		},
+		Accept: func(state int) bool { return true },
+	}
+}
+
+// HasPair needs two equal adjacent digits.
+func HasPair() Rule {
+	// state is (last digit + 1) * 2 + found
+	return Rule{
+		Name:  "has-pair",
+		Start: 0,
+		Next: func(state, digit int) (int, bool) {
+			found := state % 2
+			if state/2 == digit+1 {
+				found = 1
+			}
+
+			return (digit+1)*2 + found, true
+		},
+		Accept: func(state int) bool { return state%2 == 1 },
+	}
+}
+
+// HasExactPair needs two equal adjacent digits that are not part of a
+// longer run.
+func HasExactPair() Rule {
+	// state is last digit + 1, run length (capped at 3) and found, as digits
+	encode := func(last, run, found int) int { return last*100 + run*10 + found }
+
+	return Rule{
+		Name:  "has-exact-pair",
+		Start: 0,
+		Next: func(state, digit int) (int, bool) {
+			last, run, found := state/100, state/10%10, state%10
+
+			if last == digit+1 {
+				if run < 3 {
+					run++
+				}
+
+				return encode(last, run, found), true
+			}
+
+			if run == 2 {
+				found = 1
+			}
+
+			return encode(digit+1, 1, found), true
+		},
+		Accept: func(state int) bool { return state%10 == 1 || state/10%10 == 2 },
+	}
+}
+
+// Predicate turns any check of the whole password into a rule. Its state is
+// the whole prefix, so combine it with rules that reject early or counting
+// gets slow.
+func Predicate(name string, f func(digits []int) bool) Rule {
+	// the prefix with a leading 1, so leading zeros are kept
+	return Rule{
+		Name:  name,
+		Start: 1,
+		Next: func(state, digit int) (int, bool) {
+			return state*10 + digit, true
+		},
+		Accept: func(state int) bool {
+			text := strconv.Itoa(state)[1:]
+			digits := []int{}
+
+			for _, c := range text {
+				digits = append(digits, int(c-'0'))
+			}
+
+			return f(digits)
+		},
+	}
+}
+
+// Rules are all the rules a password with a fixed number of digits has to
+// meet. Numbers with fewer digits are padded with zeros.
+type Rules struct {
+	Digits int
+	rules  []Rule
+}
+
+func NewRules(digits int, rules ...Rule) (*Rules, error) {
+	if digits < 1 || digits > 17 {
+		return nil, fmt.Errorf("Unsupported number of digits %d", digits)
+	}
+
+	return &Rules{Digits: digits, rules: rules}, nil
+}
+
+func (r *Rules) String() string {
+	names := []string{}
+
+	for _, rule := range r.rules {
+		names = append(names, rule.Name)
+	}
+
+	return fmt.Sprintf("%d digits, %s", r.Digits, strings.Join(names, ", "))
+}
+
+func (r *Rules) digits(n int) []int {
+	result := make([]int, r.Digits)
+
+	for i := r.Digits - 1; i >= 0; i-- {
+		result[i] = n % 10
+		n /= 10
+	}
+
+	return result
+}
+
+func (r *Rules) limit() int {
+	result := 1
+
+	for i := 0; i < r.Digits; i++ {
+		result *= 10
+	}
+
+	return result
+}
+
+// Matches checks a single number by running every rule over its digits.
+func (r *Rules) Matches(n int) bool {
+	if n < 0 || n >= r.limit() {
+		return false
+	}
+
+	for _, rule := range r.rules {
+		state := rule.Start
+
+		for _, d := range r.digits(n) {
+			next, ok := rule.Next(state, d)
+			if !ok {
+				return false
+			}
+
+			state = next
+		}
+
+		if !rule.Accept(state) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Count returns how many numbers from low to high (inclusive) meet every
+// rule, without looking at every number.
+func (r *Rules) Count(low, high int) (int, error) {
+	if low < 0 || high >= r.limit() {
+		return 0, fmt.Errorf("Range %d-%d does not fit into %d digits", low, high, r.Digits)
+	}
+
+	if low > high {
+		return 0, nil
+	}
+
+	return r.countUpTo(high) - r.countUpTo(low-1), nil
+}
+
+// countUpTo is a digit DP over the rule states. While the prefix equals the
+// bound's prefix the digits are limited, after that the result only depends
+// on the position and the rule states, so it is memoized.
+func (r *Rules) countUpTo(bound int) int {
+	if bound < 0 {
+		return 0
+	}
+
+	bounds := r.digits(bound)
+	memo := map[string]int{}
+
+	var count func(pos int, states []int, tight bool) int
+
+	count = func(pos int, states []int, tight bool) int {
+		if pos == r.Digits {
+			for i, rule := range r.rules {
+				if !rule.Accept(states[i]) {
+					return 0
+				}
+			}
+
+			return 1
+		}
+
+		key := ""
+		if !tight {
+			key = stateKey(pos, states)
+
+			if result, ok := memo[key]; ok {
+				return result
+			}
+		}
+
+		max := 9
+		if tight {
+			max = bounds[pos]
+		}
+
+		result := 0
+
+		for d := 0; d <= max; d++ {
+			next := make([]int, len(states))
+			ok := true
+
+			for i, rule := range r.rules {
+				next[i], ok = rule.Next(states[i], d)
+				if !ok {
+					break
+				}
+			}
+
+			if ok {
+				result += count(pos+1, next, tight && d == max)
+			}
+		}
+
+		if !tight {
+			memo[key] = result
+		}
+
+		return result
+	}
+
+	start := []int{}
+	for _, rule := range r.rules {
+		start = append(start, rule.Start)
+	}
+
+	return count(0, start, true)
+}
+
+func stateKey(pos int, states []int) string {
+	parts := []string{strconv.Itoa(pos)}
+
+	for _, s := range states {
+		parts = append(parts, strconv.Itoa(s))
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// BruteForce counts by checking every number, for cross-checking Count.
+func (r *Rules) BruteForce(low, high int) int {
+	result := 0
+
+	for n := low; n <= high; n++ {
+		if r.Matches(n) {
+			result++
+		}
+	}
+
+	return result
+}
@@ -1,101 +1,155 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
-type Orbits map[string]string
+func assert(name string, v bool) {
+	if v {
+		fmt.Println("OK", name)
+	} else {
+		fmt.Println("FAILURE", name)
+	}
+}
 
-func load(filename string) Orbits {
-	orbits := Orbits{}
+func parseString(text string, opts ParseOptions) ([]Orbit, []error, error) {
+	return Parse(strings.NewReader(text), opts)
+}
 
-	inputFile, _ := os.OpenFile(filename, os.O_RDONLY, os.ModePerm)
-	reader := bufio.NewReader(inputFile)
+func errorLine(err error) int {
+	if e, ok := err.(*ParseError); ok {
+		return e.Line
+	}
 
-	for {
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			break // EOF
-		}
+	return 0
+}
 
-		parsedOrbit := strings.Split(line, ")")
+func test1() {
+	unix, _, err1 := parseString("COM)B\nB)C\n", ParseOptions{})
+	windows, _, err2 := parseString("COM)B\r\nB)C\r\n", ParseOptions{})
 
-		orbits[strings.Trim(parsedOrbit[1], "\n")] = parsedOrbit[0]
-	}
+	assert("CRLF parses", err1 == nil && err2 == nil)
+	assert("CRLF gives the same orbits", len(unix) == 2 && len(windows) == 2 && unix[1] == windows[1])
+}
+
+func test2() {
+	orbits, _, err := parseString("\nCOM)B\n\n  B)C  \n\n", ParseOptions{})
 
-	return orbits
+	assert("Blank lines are skipped", err == nil && len(orbits) == 2)
+	assert("Line numbers count blank lines", len(orbits) == 2 && orbits[1].Line == 4)
+	assert("Names are trimmed", len(orbits) == 2 && orbits[1].Satellite == "C")
 }
 
-func calculateOrbitDepth(name string, orbits *Orbits, depths *map[string]int) int {
-	if name == "COM" {
-		return 0
+func test3() {
+	malformed := map[string]int{
+		"COM)B\nBC\n":       2,
+		"COM)B\nB)C)D\n":    2,
+		"COM)\n":            1,
+		"COM)B\n)C\n":       2,
+		"COM)B\nB)B\n":      2,
+		"COM)B\nB)C D\n":    2,
+		"COM)B\nB)C\nX)C\n": 3,
 	}
 
-	if (*depths)[name] != 0 {
-		return (*depths)[name]
+	for text, line := range malformed {
+		_, _, err := parseString(text, ParseOptions{})
+
+		assert(fmt.Sprintf("Malformed %q fails on line %d", text, line), err != nil && errorLine(err) == line)
 	}
+}
 
-	depth := 1 + calculateOrbitDepth((*orbits)[name], orbits, depths)
+func test4() {
+	orbits, warnings, err := parseString("COM)B\nbroken\nB)C\nX)C\n", ParseOptions{Lenient: true})
 
-	(*depths)[name] = depth
+	assert("Lenient mode does not fail", err == nil)
+	assert("Lenient mode keeps good orbits", len(orbits) == 2 && orbits[1].Center == "B")
+	assert("Lenient mode warns per bad line", len(warnings) == 2 && errorLine(warnings[0]) == 2 && errorLine(warnings[1]) == 4)
+}
 
-	return depth
+func test5() {
+	_, _, err := LoadFile("does-not-exist.txt", ParseOptions{})
+
+	assert("Missing file is an error", err != nil)
 }
 
-func listAllOrbits(name string, orbits *Orbits) []string {
-	parent := (*orbits)[name]
+func test6() {
+	orbits, _, err := parseString("COM)B\nB)C\nC)B\n", ParseOptions{Lenient: true})
+	if err != nil {
+		assert("Lenient parse with a duplicate", false)
+		return
+	}
+
+	_, err = NewOrbitTree(orbits, "COM")
+
+	assert("Parsed duplicate is dropped before the tree", err == nil)
+}
 
-	if parent == "COM" {
-		return []string{"COM"}
+func writeDot(filename string, tree *OrbitTree) {
+	file, err := os.Create(filename)
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
+	defer file.Close()
 
-	return append(listAllOrbits(parent, orbits), parent)
+	if err := tree.WriteDot(file); err != nil {
+		fmt.Println(err)
+	}
 }
 
 func main() {
-	orbits := load("input.txt")
-	depths := map[string]int{}
+	out := flag.String("out", "", "directory to write orbits.dot to, nothing is written when empty")
 
-	fmt.Println(orbits)
-	fmt.Println(depths)
+	flag.Parse()
 
-	for name, _ := range orbits {
-		calculateOrbitDepth(name, &orbits, &depths)
+	orbits, _, err := LoadFile("input.txt", ParseOptions{})
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	fmt.Println(depths)
+	tree, err := NewOrbitTree(orbits, "COM")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	total := 0
+	fmt.Println(tree.Checksum())
 
-	for _, v := range depths {
-		total += v
+	me, err := tree.Parent("YOU")
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	fmt.Println(total)
-
-	santaParents := listAllOrbits("SAN", &orbits)
-	myParents := listAllOrbits("YOU", &orbits)
+	santa, err := tree.Parent("SAN")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	fmt.Println(santaParents)
-	fmt.Println(myParents)
+	transfers, err := tree.Transfers(me, santa)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	commonParentCount := 0
+	fmt.Println(transfers)
 
-	for i := 0; i < len(santaParents) || i < len(myParents); i++ {
-		if santaParents[i] == myParents[i] {
-			commonParentCount += 1
-			continue
-		} else {
-			break
-		}
+	if *out != "" {
+		writeDot(filepath.Join(*out, "orbits.dot"), tree)
 	}
 
-	fmt.Println(commonParentCount)
-	fmt.Println(len(santaParents))
-	fmt.Println(len(myParents))
+	fmt.Println("------------")
 
-	fmt.Println(len(santaParents) - commonParentCount + len(myParents) - commonParentCount)
+	test1()
+	test2()
+	test3()
+	test4()
+	test5()
+	test6()
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ParseError points at the line of the orbit map that could not be read.
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Line %d: %s", e.Line, e.Msg)
+}
+
+type ParseOptions struct {
+	// Lenient skips bad lines and duplicate satellites, they are returned as
+	// warnings instead of failing the whole map.
+	Lenient bool
+}
+
+// Parse reads one "center)satellite" orbit per line. Windows line endings,
+// surrounding spaces and blank lines are accepted.
+func Parse(r io.Reader, opts ParseOptions) ([]Orbit, []error, error) {
+	orbits := []Orbit{}
+	warnings := []error{}
+	seen := map[string]Orbit{}
+
+	fail := func(err error) error {
+		if opts.Lenient {
+			warnings = append(warnings, err)
+			return nil
+		}
+
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(strings.TrimSuffix(scanner.Text(), "\r"))
+		if text == "" {
+			continue
+		}
+
+		orbit, err := parseOrbit(text, line)
+		if err != nil {
+			if err := fail(err); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		if previous, ok := seen[orbit.Satellite]; ok {
+			msg := fmt.Sprintf("%s already orbits %s on line %d", orbit.Satellite, previous.Center, previous.Line)
+
+			if err := fail(&ParseError{Line: line, Msg: msg}); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		seen[orbit.Satellite] = orbit
+		orbits = append(orbits, orbit)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return orbits, warnings, nil
+}
+
+func parseOrbit(text string, line int) (Orbit, error) {
+	parts := strings.Split(text, ")")
+	if len(parts) != 2 {
+		return Orbit{}, &ParseError{Line: line, Msg: fmt.Sprintf("expected center)satellite, got %q", text)}
+	}
+
+	center := strings.TrimSpace(parts[0])
+	satellite := strings.TrimSpace(parts[1])
+
+	if center == "" || satellite == "" {
+		return Orbit{}, &ParseError{Line: line, Msg: fmt.Sprintf("missing object name in %q", text)}
+	}
+
+	if strings.ContainsAny(center+satellite, " \t") {
+		return Orbit{}, &ParseError{Line: line, Msg: fmt.Sprintf("object names can't contain spaces in %q", text)}
+	}
+
+	if center == satellite {
+		return Orbit{}, &ParseError{Line: line, Msg: fmt.Sprintf("%s orbits itself", center)}
+	}
+
+	return Orbit{Center: center, Satellite: satellite, Line: line}, nil
+}
+
+func LoadFile(filename string, opts ParseOptions) ([]Orbit, []error, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return Parse(file, opts)
+}
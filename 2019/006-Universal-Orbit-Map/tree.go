@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Orbit is one "center)satellite" entry, Line is where it was read from.
+type Orbit struct {
+	Center    string
+	Satellite string
+	Line      int
+}
+
+// ValidationError lists every problem found in an orbit map.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("Invalid orbit map:\n  %s", strings.Join(e.Problems, "\n  "))
+}
+
+// OrbitTree is an orbit map where every object except the root orbits
+// exactly one other object and leads back to the root.
+type OrbitTree struct {
+	Root     string
+	parent   map[string]string
+	children map[string][]string
+	depth    map[string]int
+}
+
+func NewOrbitTree(orbits []Orbit, root string) (*OrbitTree, error) {
+	t := &OrbitTree{
+		Root:     root,
+		parent:   map[string]string{},
+		children: map[string][]string{},
+		depth:    map[string]int{root: 0},
+	}
+
+	problems := []string{}
+	lines := map[string]int{}
+
+	for _, o := range orbits {
+		if o.Satellite == root {
+			problems = append(problems, fmt.Sprintf("line %d: root %s orbits %s", o.Line, root, o.Center))
+			continue
+		}
+
+		if previous, ok := t.parent[o.Satellite]; ok {
+			problems = append(problems, fmt.Sprintf("line %d: %s orbits both %s (line %d) and %s", o.Line, o.Satellite, previous, lines[o.Satellite], o.Center))
+			continue
+		}
+
+		t.parent[o.Satellite] = o.Center
+		lines[o.Satellite] = o.Line
+		t.children[o.Center] = append(t.children[o.Center], o.Satellite)
+	}
+
+	for _, c := range t.children {
+		sort.Strings(c)
+	}
+
+	// depths from the root down, whatever is left over is not connected
+	queue := []string{root}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		for _, c := range t.children[name] {
+			t.depth[c] = t.depth[name] + 1
+			queue = append(queue, c)
+		}
+	}
+
+	reported := map[string]bool{}
+
+	for _, name := range t.Objects() {
+		if _, ok := t.depth[name]; ok || reported[name] {
+			continue
+		}
+
+		// walk up until the chain ends or loops
+		chain := []string{}
+		seen := map[string]int{}
+		current := name
+
+		for {
+			if i, loop := seen[current]; loop {
+				cycle := append(chain[i:], current)
+				problems = append(problems, fmt.Sprintf("cycle %s", strings.Join(cycle, " -> ")))
+				break
+			}
+
+			if reported[current] {
+				break
+			}
+
+			seen[current] = len(chain)
+			chain = append(chain, current)
+
+			next, ok := t.parent[current]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s and everything orbiting it is not connected to %s", current, root))
+				break
+			}
+
+			current = next
+		}
+
+		for _, c := range chain {
+			reported[c] = true
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return t, nil
+}
+
+// Objects lists every object in the map, sorted.
+func (t *OrbitTree) Objects() []string {
+	names := map[string]bool{t.Root: true}
+
+	for s, c := range t.parent {
+		names[s] = true
+		names[c] = true
+	}
+
+	result := []string{}
+	for n := range names {
+		result = append(result, n)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func (t *OrbitTree) Parent(name string) (string, error) {
+	parent, ok := t.parent[name]
+	if !ok {
+		return "", fmt.Errorf("%s does not orbit anything", name)
+	}
+
+	return parent, nil
+}
+
+// Depth is the number of direct and indirect orbits of an object.
+func (t *OrbitTree) Depth(name string) (int, error) {
+	depth, ok := t.depth[name]
+	if !ok {
+		return 0, fmt.Errorf("Unknown object %s", name)
+	}
+
+	return depth, nil
+}
+
+// Checksum is the total number of direct and indirect orbits.
+func (t *OrbitTree) Checksum() int {
+	total := 0
+
+	for _, d := range t.depth {
+		total += d
+	}
+
+	return total
+}
+
+// Ancestors lists what an object orbits, from the root down to its parent.
+func (t *OrbitTree) Ancestors(name string) ([]string, error) {
+	depth, err := t.Depth(name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, depth)
+
+	for i := depth - 1; i >= 0; i-- {
+		name = t.parent[name]
+		result[i] = name
+	}
+
+	return result, nil
+}
+
+// CommonAncestor is the deepest object both a and b are in, or orbit.
+func (t *OrbitTree) CommonAncestor(a, b string) (string, error) {
+	da, err := t.Depth(a)
+	if err != nil {
+		return "", err
+	}
+
+	db, err := t.Depth(b)
+	if err != nil {
+		return "", err
+	}
+
+	for ; da > db; da-- {
+		a = t.parent[a]
+	}
+
+	for ; db > da; db-- {
+		b = t.parent[b]
+	}
+
+	for a != b {
+		a = t.parent[a]
+		b = t.parent[b]
+	}
+
+	return a, nil
+}
+
+// Transfers is the smallest number of orbital transfers that move a to b.
+func (t *OrbitTree) Transfers(a, b string) (int, error) {
+	common, err := t.CommonAncestor(a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.depth[a] + t.depth[b] - 2*t.depth[common], nil
+}
+
+// WriteDot exports the tree in graphviz format, centers point to their
+// satellites.
+func (t *OrbitTree) WriteDot(w io.Writer) error {
+	lines := []string{"digraph orbits {"}
+
+	for _, name := range t.Objects() {
+		for _, c := range t.children[name] {
+			lines = append(lines, fmt.Sprintf("  %q -> %q;", name, c))
+		}
+	}
+
+	lines = append(lines, "}")
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+
+	return err
+}
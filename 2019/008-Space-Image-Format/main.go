@@ -1,71 +1,35 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"math"
-	"strconv"
-)
-
-const Width = 25
-const Height = 6
-
-type Layer [Height][Width]int
-type Image []Layer
-
-func load(filename string) *Image {
-	data, _ := ioutil.ReadFile(filename)
-
-	img := Image{}
-
-	for i := 0; i < len(data)-1; i += Height * Width {
-		layer := Layer{}
+	"os"
+	"path/filepath"
 
-		fmt.Println(i)
-
-		for j := 0; j < Height; j++ {
-			for k := 0; k < Width; k++ {
-				index := i + j*Width + k
-
-				digit, err := strconv.Atoi(string(data[index]))
-				if err != nil {
-					panic(err)
-				}
-
-				layer[j][k] = digit
-			}
-		}
-
-		fmt.Println()
-
-		img = append(img, layer)
-	}
-
-	return &img
-}
-
-func (l *Layer) Count(digit int) int {
-	result := 0
+	"github.com/shiroyasha/advent-of-code-2017/2019/ocr"
+	"github.com/shiroyasha/advent-of-code-2017/2019/sif"
+)
 
-	for i := 0; i < Height; i++ {
-		for j := 0; j < Width; j++ {
-			if l[i][j] == digit {
-				result++
-			}
-		}
+func load(filename string, width, height int) (*sif.Image, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	return result
+	return sif.Decode(file, width, height)
 }
 
-func PrintLayer(l Layer) {
-	for i := 0; i < Height; i++ {
-		for j := 0; j < Width; j++ {
-			if l[i][j] == 2 {
+func PrintLayer(l *sif.Layer) {
+	for y := 0; y < l.Height; y++ {
+		for x := 0; x < l.Width; x++ {
+			switch l.At(x, y) {
+			case sif.Transparent:
 				fmt.Printf(" ")
-			} else if l[i][j] == 0 {
+			case sif.Black:
 				fmt.Printf("_")
-			} else if l[i][j] == 1 {
+			case sif.White:
 				fmt.Printf("#")
 			}
 		}
@@ -73,43 +37,33 @@ func PrintLayer(l Layer) {
 	}
 }
 
-func MergeLayers(img Image) Layer {
-	layer := Layer{}
-
-	// Set every pixel to transparent
-	for j := 0; j < Height; j++ {
-		for k := 0; k < Width; k++ {
-			layer[j][k] = 2
-		}
-	}
-
-	// merge
-	for i := 0; i < len(img); i++ {
-		fmt.Printf("Layer %d\n", i)
-		PrintLayer(layer)
-		PrintLayer(img[i])
-		fmt.Println()
-
-		for j := 0; j < Height; j++ {
-			for k := 0; k < Width; k++ {
-				if layer[j][k] == 2 {
-					layer[j][k] = img[i][j][k]
-				}
-
-			}
-		}
+func writePNG(filename string, l *sif.Layer) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	return layer
+	return l.WritePNG(file, 10)
 }
 
 func main() {
-	img := load("input.txt")
+	width := flag.Int("width", 25, "image width in pixels")
+	height := flag.Int("height", 6, "image height in pixels")
+	out := flag.String("out", "", "directory to write message.png and layer-0.png to, nothing is written when empty")
+
+	flag.Parse()
+
+	img, err := load("input.txt", *width, *height)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	minLayer := (*img)[0]
+	minLayer := img.Layers[0]
 	minZeroCount := math.MaxInt32
 
-	for _, l := range *img {
+	for _, l := range img.Layers {
 		zeroCount := l.Count(0)
 
 		if zeroCount <= minZeroCount {
@@ -120,5 +74,26 @@ func main() {
 
 	fmt.Println(minLayer.Count(1) * minLayer.Count(2))
 
-	PrintLayer(MergeLayers(*img))
+	message := img.MergeLayers()
+
+	PrintLayer(message)
+
+	text, err := ocr.Recognize(message.Grid())
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(text)
+
+	if *out == "" {
+		return
+	}
+
+	if err := writePNG(filepath.Join(*out, "message.png"), message); err != nil {
+		fmt.Println(err)
+	}
+
+	if err := writePNG(filepath.Join(*out, "layer-0.png"), img.Layers[0]); err != nil {
+		fmt.Println(err)
+	}
 }
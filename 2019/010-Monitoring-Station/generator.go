@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+//
+// Generated asteroid fields and visibility heat maps
+//
+// Every generator takes a seed, so a field can be recreated when it finds a
+// problem in the station selection.
+//
+
+func NewAsteroidMap(width, height int) AsteroidMap {
+	m := AsteroidMap{}
+
+	for y := 0; y < height; y++ {
+		m = append(m, make([]bool, width))
+	}
+
+	return m
+}
+
+func (m *AsteroidMap) set(p Pos) {
+	if p.Y >= 0 && p.Y < len(*m) && p.X >= 0 && p.X < len((*m)[p.Y]) {
+		(*m)[p.Y][p.X] = true
+	}
+}
+
+// RandomField places an asteroid on every cell with the given probability.
+func RandomField(width, height int, density float64, seed int64) AsteroidMap {
+	r := rand.New(rand.NewSource(seed))
+	m := NewAsteroidMap(width, height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if r.Float64() < density {
+				m.set(Pos{X: x, Y: y})
+			}
+		}
+	}
+
+	return m
+}
+
+// LineField draws dense lines between random points, lots of asteroids end
+// up hiding each other.
+func LineField(width, height, lines int, seed int64) AsteroidMap {
+	r := rand.New(rand.NewSource(seed))
+	m := NewAsteroidMap(width, height)
+
+	for i := 0; i < lines; i++ {
+		from := Pos{X: r.Intn(width), Y: r.Intn(height)}
+		to := Pos{X: r.Intn(width), Y: r.Intn(height)}
+
+		d, steps := DirectionTo(from, to)
+
+		for s := 0; s <= steps; s++ {
+			m.set(Pos{X: from.X + s*d.DX, Y: from.Y + s*d.DY})
+		}
+	}
+
+	return m
+}
+
+// RingField draws rings around the center, every asteroid is kept with the
+// given probability so the rings have gaps.
+func RingField(width, height, rings int, density float64, seed int64) AsteroidMap {
+	r := rand.New(rand.NewSource(seed))
+	m := NewAsteroidMap(width, height)
+
+	cx := float64(width-1) / 2
+	cy := float64(height-1) / 2
+	radius := math.Min(cx, cy)
+
+	for i := 1; i <= rings; i++ {
+		ring := radius * float64(i) / float64(rings)
+
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				distance := math.Hypot(float64(x)-cx, float64(y)-cy)
+
+				if math.Abs(distance-ring) < 0.5 && r.Float64() < density {
+					m.set(Pos{X: x, Y: y})
+				}
+			}
+		}
+	}
+
+	return m
+}
+
+func (m *AsteroidMap) String() string {
+	lines := []string{}
+
+	for _, row := range *m {
+		line := ""
+
+		for _, asteroid := range row {
+			if asteroid {
+				line += "#"
+			} else {
+				line += "."
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// VisibilityCounts is the number of asteroids visible from every cell of
+// the map, with an asteroid or without.
+func (m *AsteroidMap) VisibilityCounts() [][]int {
+	result := [][]int{}
+
+	for y, row := range *m {
+		counts := []int{}
+
+		for x := range row {
+			counts = append(counts, len(m.ListVisible(Pos{X: x, Y: y})))
+		}
+
+		result = append(result, counts)
+	}
+
+	return result
+}
+
+func countRange(counts [][]int) (int, int) {
+	min, max := -1, 0
+
+	for _, row := range counts {
+		for _, v := range row {
+			if min == -1 || v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+	}
+
+	return min, max
+}
+
+// level scales a count between the least and the most visible cells into
+// 0..levels-1.
+func level(c, min, max, levels int) int {
+	if max == min {
+		return levels - 1
+	}
+
+	return (c - min) * (levels - 1) / (max - min)
+}
+
+const heatRamp = " .:-=+*%@"
+
+// HeatMapASCII shades every cell by its visibility, darkest is the most.
+func (m *AsteroidMap) HeatMapASCII() string {
+	counts := m.VisibilityCounts()
+	min, max := countRange(counts)
+	lines := []string{}
+
+	for _, row := range counts {
+		line := ""
+
+		for _, c := range row {
+			line += string(heatRamp[level(c, min, max, len(heatRamp))])
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// WriteHeatMapPNG draws the visibility of every cell from blue (least) to
+// red (most). Asteroids get a white dot in the middle.
+func (m *AsteroidMap) WriteHeatMapPNG(w io.Writer, scale int) error {
+	if scale < 3 {
+		return fmt.Errorf("Scale has to be at least 3, got %d", scale)
+	}
+
+	counts := m.VisibilityCounts()
+	min, max := countRange(counts)
+
+	height := len(counts)
+	width := 0
+	if height > 0 {
+		width = len(counts[0])
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width*scale, height*scale))
+
+	for y, row := range counts {
+		for x, c := range row {
+			l := uint8(level(c, min, max, 256))
+			heat := color.RGBA{R: l, G: 0x20, B: 0xff - l, A: 0xff}
+
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.Set(x*scale+dx, y*scale+dy, heat)
+				}
+			}
+
+			if m.Has(Pos{X: x, Y: y}) {
+				img.Set(x*scale+scale/2, y*scale+scale/2, color.White)
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
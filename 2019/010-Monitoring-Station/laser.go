@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type LaserOptions struct {
+	Start     Direction // first direction to fire at, straight up by default
+	Clockwise bool
+}
+
+func DefaultLaserOptions() LaserOptions {
+	return LaserOptions{Start: Direction{DX: 0, DY: -1}, Clockwise: true}
+}
+
+type Shot struct {
+	N        int // 1 based
+	Pos      Pos
+	Rotation int // 1 based
+}
+
+// Laser vaporizes one asteroid per direction per rotation, the closest
+// first.
+type Laser struct {
+	Station Pos
+
+	rays       map[Direction][]Pos
+	directions []Direction
+	next       int
+	rotation   int
+	shots      int
+}
+
+func NewLaser(m *AsteroidMap, station Pos, opts LaserOptions) (*Laser, error) {
+	if opts.Start == (Direction{}) {
+		return nil, fmt.Errorf("Start direction can't be zero")
+	}
+
+	rays := m.Rays(station)
+	directions := SortedDirections(rays)
+
+	sort.SliceStable(directions, func(i, j int) bool {
+		return rotatesBefore(opts.Start, directions[i], directions[j], opts.Clockwise)
+	})
+
+	return &Laser{Station: station, rays: rays, directions: directions, rotation: 1}, nil
+}
+
+// rotatesBefore tells if the laser turning from start meets a before b.
+// Screen coordinates (y down) make a positive cross product clockwise.
+func rotatesBefore(start, a, b Direction, clockwise bool) bool {
+	sign := 1
+	if !clockwise {
+		sign = -1
+	}
+
+	cross := func(u, v Direction) int { return sign * (u.DX*v.DY - u.DY*v.DX) }
+	dot := func(u, v Direction) int { return u.DX*v.DX + u.DY*v.DY }
+
+	half := func(d Direction) int {
+		c := cross(start, d)
+
+		if c > 0 || (c == 0 && dot(start, d) > 0) {
+			return 0
+		}
+
+		return 1
+	}
+
+	if half(a) != half(b) {
+		return half(a) < half(b)
+	}
+
+	return cross(a, b) > 0
+}
+
+// Next vaporizes the next asteroid. ok is false once nothing is left.
+func (l *Laser) Next() (Shot, bool) {
+	if len(l.directions) == 0 {
+		return Shot{}, false
+	}
+
+	for tries := 0; tries <= len(l.directions); tries++ {
+		if l.next == len(l.directions) {
+			l.next = 0
+			l.rotation++
+		}
+
+		d := l.directions[l.next]
+		l.next++
+
+		if ray := l.rays[d]; len(ray) > 0 {
+			l.rays[d] = ray[1:]
+			l.shots++
+
+			return Shot{N: l.shots, Pos: ray[0], Rotation: l.rotation}, true
+		}
+	}
+
+	return Shot{}, false
+}
+
+// StopAfter fires at most n more shots.
+func (l *Laser) StopAfter(n int) []Shot {
+	result := []Shot{}
+
+	for i := 0; i < n; i++ {
+		shot, ok := l.Next()
+		if !ok {
+			break
+		}
+
+		result = append(result, shot)
+	}
+
+	return result
+}
+
+// All fires until every asteroid is gone.
+func (l *Laser) All() []Shot {
+	result := []Shot{}
+
+	for shot, ok := l.Next(); ok; shot, ok = l.Next() {
+		result = append(result, shot)
+	}
+
+	return result
+}
+
+// Annotate draws the map with the order of every shot. The station is @,
+// asteroids that survived are x.
+func Annotate(m *AsteroidMap, station Pos, shots []Shot) string {
+	order := map[Pos]int{}
+	for _, s := range shots {
+		order[s.Pos] = s.N
+	}
+
+	width := len(strconv.Itoa(len(shots))) + 1
+	cell := func(s string) string { return fmt.Sprintf("%*s", width, s) }
+
+	lines := []string{}
+
+	for y, row := range *m {
+		line := ""
+
+		for x, asteroid := range row {
+			p := Pos{X: x, Y: y}
+
+			switch n, shot := order[p]; {
+			case p == station:
+				line += cell("@")
+			case shot:
+				line += cell(strconv.Itoa(n))
+			case asteroid:
+				line += cell("x")
+			default:
+				line += cell(".")
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func WriteShotsCSV(w io.Writer, shots []Shot) error {
+	out := csv.NewWriter(w)
+
+	if err := out.Write([]string{"n", "x", "y", "rotation"}); err != nil {
+		return err
+	}
+
+	for _, s := range shots {
+		record := []string{strconv.Itoa(s.N), strconv.Itoa(s.Pos.X), strconv.Itoa(s.Pos.Y), strconv.Itoa(s.Rotation)}
+
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+
+	return out.Error()
+}
@@ -2,10 +2,10 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
-	"math"
 	"os"
-	"sort"
+	"path/filepath"
 )
 
 type AsteroidMap [][]bool
@@ -15,9 +15,6 @@ type Pos struct {
 	Y int
 }
 
-var nukes = [][]int{}
-var nuked = 0
-
 func load(filename string) AsteroidMap {
 	m := [][]bool{}
 
@@ -33,23 +30,19 @@ func load(filename string) AsteroidMap {
 		}
 
 		row := []bool{}
-		nukeRow := []int{}
 
 		for i := 0; i < len(line); i++ {
 
 			if line[i] == '.' {
 				row = append(row, false)
-				nukeRow = append(nukeRow, -1)
 			}
 
 			if line[i] == '#' {
 				row = append(row, true)
-				nukeRow = append(nukeRow, -1)
 			}
 		}
 
 		m = append(m, row)
-		nukes = append(nukes, nukeRow)
 	}
 
 	return AsteroidMap(m)
@@ -73,48 +66,6 @@ func (m *AsteroidMap) Display(pos Pos) {
 	}
 }
 
-func (m *AsteroidMap) Nukes(pos Pos) {
-	if len(*m) != len(nukes) {
-		panic("AAAA")
-	}
-
-	if len((*m)[0]) != len(nukes[0]) {
-		fmt.Println(len(nukes[0]))
-		panic(len((*m)[0]))
-	}
-	for j, row := range nukes {
-		for i, c := range row {
-			if j == pos.Y && i == pos.X {
-				fmt.Print(" % ")
-				continue
-			}
-
-			if c > 0 {
-				fmt.Printf("%3d", c)
-				continue
-			}
-
-			if (*m)[j][i] {
-				fmt.Printf("  x")
-			} else {
-				fmt.Printf("  .")
-			}
-
-			// fmt.Printf("   ")
-
-		}
-
-		fmt.Println()
-	}
-}
-
-func (m *AsteroidMap) Nuke(pos Pos) {
-	nukes[pos.Y][pos.X] = nuked
-	nuked++
-
-	(*m)[pos.Y][pos.X] = false
-}
-
 func (m *AsteroidMap) Each(f func(Pos)) {
 	for i := 0; i < len(*m); i++ {
 		for j := 0; j < len((*m)[i]); j++ {
@@ -125,195 +76,166 @@ func (m *AsteroidMap) Each(f func(Pos)) {
 	}
 }
 
-func (m *AsteroidMap) InBetween(p1, p, p2 Pos) bool {
-	if p1.X == p2.X {
-		if p1.X != p.X {
-			return false
-		}
-
-		if p1.Y <= p.Y && p.Y <= p2.Y {
-			return true
-		}
-
-		if p1.Y >= p.Y && p.Y >= p2.Y {
-			return true
-		}
-
-		return false
-	}
-
-	if p1.Y == p2.Y {
-		if p1.Y != p.Y {
-			return false
-		}
-
-		if p1.X <= p.X && p.X <= p2.X {
-			return true
-		}
+func (m *AsteroidMap) Has(p Pos) bool {
+	return p.Y >= 0 && p.Y < len(*m) && p.X >= 0 && p.X < len((*m)[p.Y]) && (*m)[p.Y][p.X]
+}
 
-		if p1.X >= p.X && p.X >= p2.X {
-			return true
-		}
+// ListVisible returns the closest asteroid on every ray from p, clockwise
+// from straight up.
+func (m *AsteroidMap) ListVisible(p Pos) []Pos {
+	rays := m.Rays(p)
+	result := []Pos{}
 
-		return false
+	for _, d := range SortedDirections(rays) {
+		result = append(result, rays[d][0])
 	}
 
-	a1 := p1.X - p2.X
-	a2 := p1.X - p.X
-
-	b1 := p1.Y - p2.Y
-	b2 := p1.Y - p.Y
+	return result
+}
 
-	if a1*b2 == a2*b1 {
-		return ((p1.X <= p.X && p.X <= p2.X) || (p1.X >= p.X && p.X >= p2.X)) && ((p1.Y <= p.Y && p.Y <= p2.Y) || (p1.Y >= p.Y && p.Y >= p2.Y))
+func assert(name string, v bool) {
+	if v {
+		fmt.Println("OK", name)
 	} else {
-		return false
+		fmt.Println("FAILURE", name)
 	}
 }
 
-func (m *AsteroidMap) CanSee(p1, p2 Pos) bool {
-	hasSomethingInBetween := true
-
-	m.Each(func(p Pos) {
-		if p1 == p || p2 == p {
-			return
-		}
-
-		ok := m.InBetween(p1, p, p2)
-
-		// fmt.Println("InBetween", p1, p, p2, ok)
+func test1() {
+	m := AsteroidMap{{true, false}}
 
-		if ok {
-			hasSomethingInBetween = false
-		}
-	})
+	laser, err := NewLaser(&m, Pos{X: 0, Y: 0}, DefaultLaserOptions())
+	_, ok := laser.Next()
 
-	return hasSomethingInBetween
+	assert("lone station has nothing to shoot", err == nil && !ok)
+	assert("lone station shoots nothing in total", len(laser.All()) == 0)
 }
 
-func (m *AsteroidMap) ListVisible(p Pos) []Pos {
-	result := []Pos{}
+func test2() {
+	m := AsteroidMap{{true, true, true}, {false, true, false}}
 
-	m.Each(func(p2 Pos) {
-		if m.CanSee(p, p2) {
-			result = append(result, p2)
-		}
-	})
+	laser, _ := NewLaser(&m, Pos{X: 1, Y: 0}, DefaultLaserOptions())
+	shots := laser.All()
 
-	return result
+	assert("laser shoots every other asteroid", len(shots) == 3)
+	assert("laser starts clockwise from up", len(shots) == 3 && shots[0].Pos == Pos{X: 2, Y: 0} && shots[1].Pos == Pos{X: 1, Y: 1})
+	assert("laser ends with nothing left", len(laser.StopAfter(1)) == 0)
 }
 
-func CompareAngles(laserPos Pos, p1 Pos, p2 Pos) bool {
-	x1 := laserPos.X - p1.X
-	y1 := laserPos.Y - p1.Y
-
-	x2 := laserPos.X - p2.X
-	y2 := laserPos.Y - p2.Y
-
-	if x1 == 0 && y1 > 0 {
-		return true
-	}
-
-	if x2 == 0 {
-		return !CompareAngles(laserPos, p2, p1)
-	}
-
-	if x1 > 0 && x2 > 0 {
-		return math.Atan(float64(y1)/float64(x1)) < math.Atan(float64(y2)/float64(x2))
-	}
-
-	if x1 < 0 && x2 < 0 {
-		return math.Atan(float64(y1)/float64(x1)) < math.Atan(float64(y2)/float64(x2))
-	}
+func test3() {
+	m := load("input1.txt")
+	station, count, err := m.BestStation()
 
-	return x1 < x2
+	assert("input1.txt best station", err == nil && station == Pos{X: 3, Y: 4} && count == 8)
 }
 
-func SortByAngle(laserPos Pos, positions []Pos) []Pos {
-	sort.SliceStable(positions, func(i, j int) bool {
-		return CompareAngles(laserPos, positions[i], positions[j])
-	})
+func test4() {
+	m := load("input2.txt")
+	station, count, err := m.BestStation()
 
-	return positions
+	assert("input2.txt best station", err == nil && station == Pos{X: 1, Y: 1} && count == 15)
 }
 
 func main() {
-	m := load("input.txt")
-
-	result := map[Pos]int{}
+	out := flag.String("out", "", "directory to write vaporized.csv and heatmap.png to, nothing is written when empty")
 
-	m.Each(func(p1 Pos) {
-		m.Each(func(p2 Pos) {
-			if p1 == p2 {
-				return
-			}
+	flag.Parse()
 
-			can := m.CanSee(p1, p2)
+	test1()
+	test2()
+	test3()
+	test4()
 
-			if can {
-				result[p1]++
-			}
-		})
-	})
-
-	max := 0
-	p := Pos{}
-
-	for k, v := range result {
-		fmt.Print(k)
-		fmt.Println(v)
+	m := load("input.txt")
 
-		if v > max {
-			max = v
-			p = k
-		}
+	p, max, err := m.BestStation()
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
+	fmt.Println(p)
 	fmt.Println(max)
 	fmt.Println("-------------------------")
 
 	// Part 2
 
-	laserPos := p
-	m.Nuke(laserPos)
+	laser, err := NewLaser(&m, p, DefaultLaserOptions())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	nukeOrder := []Pos{}
+	shots := laser.StopAfter(200)
+	if len(shots) < 200 {
+		fmt.Println("Less than 200 asteroids were vaporized")
+		return
+	}
 
-	for {
-		visible := m.ListVisible(laserPos)
-		fmt.Println(visible)
+	fmt.Println(shots[199].Pos)
+	fmt.Println(shots[199].Pos.X*100 + shots[199].Pos.Y)
 
-		if len(visible) == 0 {
-			break
-		}
+	shots = append(shots, laser.All()...)
 
-		nukeOrder = append(nukeOrder, SortByAngle(laserPos, visible)...)
+	fmt.Println(Annotate(&m, p, shots))
 
-		for _, n := range nukeOrder {
-			m.Nuke(n)
+	if *out != "" {
+		writeShots(filepath.Join(*out, "vaporized.csv"), shots)
+	}
 
-			// cmd := exec.Command("clear")
-			// cmd.Stdout = os.Stdout
-			// cmd.Run()
+	generated(*out)
+}
 
-			// m.Nukes(laserPos)
+func writeShots(filename string, shots []Shot) {
+	file, err := os.Create(filename)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer file.Close()
 
-			// time.Sleep(50 * time.Millisecond)
+	if err := WriteShotsCSV(file, shots); err != nil {
+		fmt.Println(err)
+	}
+}
 
-			if nuked > 200 {
-				break
-			}
-		}
+// generated shows the generated fields, the heat map of the input is written
+// to dir when it is set.
+func generated(dir string) {
+	fields := map[string]AsteroidMap{
+		"random": RandomField(30, 20, 0.3, 1),
+		"lines":  LineField(30, 20, 8, 1),
+		"rings":  RingField(30, 20, 4, 0.7, 1),
+	}
+
+	for _, name := range []string{"random", "lines", "rings"} {
+		m := fields[name]
 
-		if nuked > 200 {
-			break
+		station, count, err := m.BestStation()
+		if err != nil {
+			fmt.Println(name, err)
+			continue
 		}
+
+		fmt.Println(name, station, count)
+		fmt.Println(m.String())
+		fmt.Println(m.HeatMapASCII())
+	}
+
+	if dir == "" {
+		return
 	}
 
-	fmt.Println(len(nukeOrder))
-	fmt.Println(nukeOrder)
-	fmt.Println(nukeOrder[199])
-	fmt.Println(nukeOrder[199].X*100 + nukeOrder[199].Y)
+	m := load("input.txt")
 
-	m.Nukes(laserPos)
+	file, err := os.Create(filepath.Join(dir, "heatmap.png"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer file.Close()
+
+	if err := m.WriteHeatMapPNG(file, 10); err != nil {
+		fmt.Println(err)
+	}
 }
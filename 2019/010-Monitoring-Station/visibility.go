@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+//
+// Exact line of sight
+//
+// Every asteroid seen from a station lies on a ray given by its offset
+// divided by the gcd of the coordinates. Asteroids on the same ray hide each
+// other, so only the closest one is visible.
+//
+
+// Direction is an offset reduced by the gcd of its coordinates.
+type Direction struct {
+	DX int
+	DY int
+}
+
+func gcd(a, b int) int {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// DirectionTo returns the ray from one position to another and how many
+// steps along it the target is.
+func DirectionTo(from, to Pos) (Direction, int) {
+	dx := to.X - from.X
+	dy := to.Y - from.Y
+
+	steps := gcd(dx, dy)
+	if steps == 0 {
+		return Direction{}, 0
+	}
+
+	return Direction{DX: dx / steps, DY: dy / steps}, steps
+}
+
+// half is 0 from straight up clockwise to just before straight down, and 1
+// for the rest. y grows downwards.
+func (d Direction) half() int {
+	if d.DX > 0 || (d.DX == 0 && d.DY < 0) {
+		return 0
+	}
+
+	return 1
+}
+
+// Before orders directions clockwise starting from straight up.
+func (d Direction) Before(other Direction) bool {
+	if d.half() != other.half() {
+		return d.half() < other.half()
+	}
+
+	return d.DX*other.DY-d.DY*other.DX > 0
+}
+
+// Rays groups the other asteroids by their direction from the station,
+// closest first.
+func (m *AsteroidMap) Rays(station Pos) map[Direction][]Pos {
+	rays := map[Direction][]Pos{}
+	steps := map[Pos]int{}
+
+	m.Each(func(p Pos) {
+		if p == station {
+			return
+		}
+
+		d, s := DirectionTo(station, p)
+
+		rays[d] = append(rays[d], p)
+		steps[p] = s
+	})
+
+	for _, ray := range rays {
+		sort.Slice(ray, func(i, j int) bool { return steps[ray[i]] < steps[ray[j]] })
+	}
+
+	return rays
+}
+
+// SortedDirections lists the keys of rays clockwise from straight up.
+func SortedDirections(rays map[Direction][]Pos) []Direction {
+	result := []Direction{}
+
+	for d := range rays {
+		result = append(result, d)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+
+	return result
+}
+
+func (m *AsteroidMap) VisibleCount(station Pos) int {
+	count := map[Direction]bool{}
+
+	m.Each(func(p Pos) {
+		if p != station {
+			d, _ := DirectionTo(station, p)
+			count[d] = true
+		}
+	})
+
+	return len(count)
+}
+
+// BestStation returns the asteroid that sees the most other asteroids. Ties
+// go to the first one in reading order.
+func (m *AsteroidMap) BestStation() (Pos, int, error) {
+	best := Pos{}
+	max := -1
+
+	m.Each(func(p Pos) {
+		if count := m.VisibleCount(p); count > max {
+			best = p
+			max = count
+		}
+	})
+
+	if max == -1 {
+		return Pos{}, 0, fmt.Errorf("Map has no asteroids")
+	}
+
+	return best, max, nil
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/shiroyasha/advent-of-code-2017/2019/ocr"
+)
+
+const (
+	Black = 0
+	White = 1
+)
+
+const (
+	TurnLeft  = 0
+	TurnRight = 1
+)
+
+// Brain decides what the robot does on a panel of the given color. done is
+// set when the brain has nothing more to say.
+type Brain interface {
+	Decide(color int) (paint int, turn int, done bool, err error)
+}
+
+// IntcodeBrain is driven by the puzzle program.
+type IntcodeBrain struct {
+	process *Process
+}
+
+func NewIntcodeBrain(code []int) *IntcodeBrain {
+	return &IntcodeBrain{process: NewProcess(code, []int{})}
+}
+
+func (b *IntcodeBrain) Decide(color int) (int, int, bool, error) {
+	p := b.process
+
+	p.AddInput(color)
+
+	for len(p.output)-p.outputPointer < 2 && !p.halted {
+		position := p.position
+
+		if err := p.RunTilInterupt(); err != nil {
+			return 0, 0, false, err
+		}
+
+		if p.position == position && len(p.output)-p.outputPointer < 2 && !p.halted {
+			return 0, 0, false, fmt.Errorf("Brain is waiting for more input at %d", p.position)
+		}
+	}
+
+	if p.halted {
+		return 0, 0, true, nil
+	}
+
+	return p.NextOutput(), p.NextOutput(), false, nil
+}
+
+// FuncBrain wraps a Go function, handy for testing the robot without intcode.
+type FuncBrain func(color int) (paint int, turn int, done bool)
+
+func (f FuncBrain) Decide(color int) (int, int, bool, error) {
+	paint, turn, done := f(color)
+
+	return paint, turn, done, nil
+}
+
+type PaintEvent struct {
+	Step  int
+	Pos   Point
+	Color int
+}
+
+type PaintingRobot struct {
+	brain   Brain
+	pos     Point
+	dir     Point
+	hull    map[Point]int
+	steps   int
+	History []PaintEvent
+}
+
+// NewPaintingRobot starts at the origin facing up (y grows upwards).
+func NewPaintingRobot(brain Brain) *PaintingRobot {
+	return &PaintingRobot{
+		brain: brain,
+		dir:   Point{X: 0, Y: 1},
+		hull:  map[Point]int{},
+	}
+}
+
+// SetPanel colors a panel before the robot starts, without counting it as
+// painted.
+func (r *PaintingRobot) SetPanel(pos Point, color int) {
+	r.hull[pos] = color
+}
+
+func (r *PaintingRobot) Position() Point {
+	return r.pos
+}
+
+// Step paints the current panel, turns and moves forward. Returns false when
+// the brain is done.
+func (r *PaintingRobot) Step() (bool, error) {
+	paint, turn, done, err := r.brain.Decide(r.hull[r.pos])
+	if err != nil || done {
+		return false, err
+	}
+
+	if paint != Black && paint != White {
+		return false, fmt.Errorf("Unknown color %d", paint)
+	}
+
+	r.steps++
+	r.hull[r.pos] = paint
+	r.History = append(r.History, PaintEvent{Step: r.steps, Pos: r.pos, Color: paint})
+
+	switch turn {
+	case TurnLeft:
+		r.dir = Point{X: -r.dir.Y, Y: r.dir.X}
+	case TurnRight:
+		r.dir = Point{X: r.dir.Y, Y: -r.dir.X}
+	default:
+		return false, fmt.Errorf("Unknown turn %d", turn)
+	}
+
+	r.pos.X += r.dir.X
+	r.pos.Y += r.dir.Y
+
+	return true, nil
+}
+
+// Run steps until the brain is done or maxSteps is reached.
+func (r *PaintingRobot) Run(maxSteps int) error {
+	for i := 0; i < maxSteps; i++ {
+		more, err := r.Step()
+		if err != nil {
+			return err
+		}
+
+		if !more {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Robot did not stop after %d steps", maxSteps)
+}
+
+// PaintedCount is the number of panels painted at least once.
+func (r *PaintingRobot) PaintedCount() int {
+	painted := map[Point]bool{}
+
+	for _, e := range r.History {
+		painted[e.Pos] = true
+	}
+
+	return len(painted)
+}
+
+// PanelHistory lists every color a panel was painted, in order.
+func (r *PaintingRobot) PanelHistory(pos Point) []int {
+	result := []int{}
+
+	for _, e := range r.History {
+		if e.Pos == pos {
+			result = append(result, e.Color)
+		}
+	}
+
+	return result
+}
+
+// Grid returns the white panels row by row from the top, cropped to the
+// painted area.
+func (r *PaintingRobot) Grid() [][]bool {
+	first := true
+	min, max := Point{}, Point{}
+
+	for pos, c := range r.hull {
+		if c != White {
+			continue
+		}
+
+		if first {
+			min, max = pos, pos
+			first = false
+		}
+
+		if pos.X < min.X {
+			min.X = pos.X
+		}
+		if pos.Y < min.Y {
+			min.Y = pos.Y
+		}
+		if pos.X > max.X {
+			max.X = pos.X
+		}
+		if pos.Y > max.Y {
+			max.Y = pos.Y
+		}
+	}
+
+	if first {
+		return [][]bool{}
+	}
+
+	grid := [][]bool{}
+
+	for y := max.Y; y >= min.Y; y-- {
+		row := []bool{}
+
+		for x := min.X; x <= max.X; x++ {
+			row = append(row, r.hull[Point{X: x, Y: y}] == White)
+		}
+
+		grid = append(grid, row)
+	}
+
+	return grid
+}
+
+func (r *PaintingRobot) ASCII() string {
+	lines := []string{}
+
+	for _, row := range r.Grid() {
+		line := ""
+
+		for _, white := range row {
+			if white {
+				line += "#"
+			} else {
+				line += "."
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// WritePNG draws every panel as a scale x scale square with a one panel
+// black border.
+func (r *PaintingRobot) WritePNG(w io.Writer, scale int) error {
+	grid := r.Grid()
+
+	height := len(grid) + 2
+	width := 2
+	if len(grid) > 0 {
+		width += len(grid[0])
+	}
+
+	img := image.NewGray(image.Rect(0, 0, width*scale, height*scale))
+
+	for y, row := range grid {
+		for x, white := range row {
+			if !white {
+				continue
+			}
+
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetGray((x+1)*scale+dx, (y+1)*scale+dy, color.Gray{Y: 0xff})
+				}
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// Registration reads the letters painted on the hull.
+func (r *PaintingRobot) Registration() (string, error) {
+	return ocr.Recognize(r.Grid())
+}
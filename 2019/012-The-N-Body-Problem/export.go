@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//
+// Streaming states and plotting trajectories
+//
+// The writers have a Report method that fits Simulation.Run. The first
+// error is kept and returned by Flush, later states are dropped.
+//
+
+// CSVWriter writes one row per body per state: step, body, the positions
+// and then the velocities.
+type CSVWriter struct {
+	out    *csv.Writer
+	axes   []string
+	header bool
+	err    error
+}
+
+func NewCSVWriter(w io.Writer, axes []string) *CSVWriter {
+	return &CSVWriter{out: csv.NewWriter(w), axes: axes}
+}
+
+func (c *CSVWriter) Report(state State) {
+	if c.err != nil {
+		return
+	}
+
+	if !c.header {
+		header := []string{"step", "body"}
+
+		for _, a := range c.axes {
+			header = append(header, "pos_"+a)
+		}
+
+		for _, a := range c.axes {
+			header = append(header, "vel_"+a)
+		}
+
+		c.err = c.out.Write(header)
+		c.header = true
+	}
+
+	for i := range state.Pos {
+		row := []string{strconv.Itoa(state.Step), strconv.Itoa(i)}
+
+		for _, v := range state.Pos[i] {
+			row = append(row, strconv.Itoa(v))
+		}
+
+		for _, v := range state.Vel[i] {
+			row = append(row, strconv.Itoa(v))
+		}
+
+		if c.err == nil {
+			c.err = c.out.Write(row)
+		}
+	}
+}
+
+func (c *CSVWriter) Flush() error {
+	c.out.Flush()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	return c.out.Error()
+}
+
+// JSONWriter writes one JSON object per state and line.
+type JSONWriter struct {
+	enc *json.Encoder
+	err error
+}
+
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{enc: json.NewEncoder(w)}
+}
+
+func (j *JSONWriter) Report(state State) {
+	if j.err == nil {
+		j.err = j.enc.Encode(state)
+	}
+}
+
+func (j *JSONWriter) Flush() error {
+	return j.err
+}
+
+var plotColors = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b"}
+
+// WritePlotSVG draws one panel per axis with the position of every body
+// over time. A dashed line marks the period of the axis, periods can be
+// nil.
+func WritePlotSVG(w io.Writer, axes []string, states []State, periods []int) error {
+	if len(states) == 0 {
+		return fmt.Errorf("No states to plot")
+	}
+
+	const width, panel, margin = 800, 200, 40
+
+	first := states[0].Step
+	last := states[len(states)-1].Step
+	if last == first {
+		last++
+	}
+
+	x := func(step int) float64 {
+		return margin + float64(step-first)*float64(width-2*margin)/float64(last-first)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\">\n", width, panel*len(axes))
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n")
+
+	for axis, name := range axes {
+		top := axis * panel
+
+		min, max := states[0].Pos[0][axis], states[0].Pos[0][axis]
+
+		for _, s := range states {
+			for _, pos := range s.Pos {
+				if pos[axis] < min {
+					min = pos[axis]
+				}
+				if pos[axis] > max {
+					max = pos[axis]
+				}
+			}
+		}
+
+		if max == min {
+			max++
+		}
+
+		y := func(v int) float64 {
+			return float64(top+panel-margin/2) - float64(v-min)*float64(panel-margin)/float64(max-min)
+		}
+
+		fmt.Fprintf(&b, "<text x=\"5\" y=\"%d\" font-family=\"monospace\">%s</text>\n", top+20, name)
+		fmt.Fprintf(&b, "<line x1=\"%d\" y1=\"%.1f\" x2=\"%d\" y2=\"%.1f\" stroke=\"#ccc\"/>\n", margin, y(0), width-margin, y(0))
+
+		for body := range states[0].Pos {
+			points := []string{}
+
+			for _, s := range states {
+				points = append(points, fmt.Sprintf("%.1f,%.1f", x(s.Step), y(s.Pos[body][axis])))
+			}
+
+			color := plotColors[body%len(plotColors)]
+
+			fmt.Fprintf(&b, "<polyline fill=\"none\" stroke=\"%s\" points=\"%s\"/>\n", color, strings.Join(points, " "))
+		}
+
+		if axis < len(periods) && periods[axis] > 0 {
+			for p := periods[axis]; p <= last; p += periods[axis] {
+				if p < first {
+					continue
+				}
+
+				fmt.Fprintf(&b, "<line x1=\"%.1f\" y1=\"%d\" x2=\"%.1f\" y2=\"%d\" stroke=\"black\" stroke-dasharray=\"4 4\"/>\n", x(p), top+margin/2, x(p), top+panel-margin/2)
+				fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%d\" font-family=\"monospace\" font-size=\"10\">%d</text>\n", x(p)+3, top+margin/2+10, p)
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
@@ -1,25 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
 )
 
-type Body struct {
-	pos, vel Vec
-}
-
-type Vec struct {
-	X, Y, Z int
-}
-
-func Add(v1, v2 Vec) Vec {
-	return Vec{
-		X: v1.X + v2.X,
-		Y: v1.Y + v2.Y,
-		Z: v1.Z + v2.Z,
-	}
-}
-
 func Abs(i int) int {
 	if i < 0 {
 		return -i
@@ -28,10 +15,6 @@ func Abs(i int) int {
 	return i
 }
 
-func Energy(v Vec) int {
-	return Abs(v.X) + Abs(v.Y) + Abs(v.Z)
-}
-
 func gravityOnAxis(a, b int) int {
 	if a < b {
 		return 1
@@ -44,209 +27,123 @@ func gravityOnAxis(a, b int) int {
 	return 0
 }
 
-func gravity(p1, p2 Vec) Vec {
-	return Vec{
-		X: gravityOnAxis(p1.X, p2.X),
-		Y: gravityOnAxis(p1.Y, p2.Y),
-		Z: gravityOnAxis(p1.Z, p2.Z),
-	}
+func lcm(a, b int) int {
+	return a * b / gcd(a, b)
 }
 
-func part1() {
-	steps := 1000
-	bodies := []Body{
-		Body{
-			pos: Vec{X: 5, Y: 4, Z: 4},
-			vel: Vec{},
-		},
-		Body{
-			pos: Vec{X: -11, Y: -11, Z: -3},
-			vel: Vec{},
-		},
-		Body{
-			pos: Vec{X: 0, Y: 7, Z: 0},
-			vel: Vec{},
-		},
-		Body{
-			pos: Vec{X: -13, Y: 2, Z: 10},
-			vel: Vec{},
-		},
-	}
-
-	for step := 0; step < steps; step++ {
-		// apply gravity
-		for i, b1 := range bodies {
-			acc := Vec{}
-
-			for _, b2 := range bodies {
-				acc = Add(acc, gravity(b1.pos, b2.pos))
-			}
-
-			bodies[i].vel = Add(bodies[i].vel, acc)
-		}
-
-		// apply velocity
-
-		for i, b := range bodies {
-			bodies[i].pos = Add(b.pos, b.vel)
-		}
-
-		fmt.Println(bodies[0].pos.X)
+func gcd(a, b int) int {
+	if a == 0 {
+		return b
 	}
 
-	total := 0
+	return gcd(b%a, a)
+}
 
-	for _, b := range bodies {
-		total += Energy(b.pos) * Energy(b.vel)
+func part1() {
+	sim, err := ParseFile("input.txt")
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	fmt.Println(total)
+	sim.Run(1000, 0, nil)
+
+	fmt.Println(sim.Energy())
 }
 
 func part2() {
 	fmt.Println("---- Part 2 ----")
 
-	start := []Body{
-		Body{
-			pos: Vec{X: 5, Y: 4, Z: 4},
-			vel: Vec{},
-		},
-		Body{
-			pos: Vec{X: -11, Y: -11, Z: -3},
-			vel: Vec{},
-		},
-		Body{
-			pos: Vec{X: 0, Y: 7, Z: 0},
-			vel: Vec{},
-		},
-		Body{
-			pos: Vec{X: -13, Y: 2, Z: 10},
-			vel: Vec{},
-		},
-	}
-
-	bodies := []Body{
-		Body{
-			pos: Vec{X: 5, Y: 4, Z: 4},
-			vel: Vec{},
-		},
-		Body{
-			pos: Vec{X: -11, Y: -11, Z: -3},
-			vel: Vec{},
-		},
-		Body{
-			pos: Vec{X: 0, Y: 7, Z: 0},
-			vel: Vec{},
-		},
-		Body{
-			pos: Vec{X: -13, Y: 2, Z: 10},
-			vel: Vec{},
-		},
-	}
-
-	xDone := false
-	yDone := false
-	zDone := false
-
-	xCycle := 0
-	yCycle := 0
-	zCycle := 0
-
-	for {
-		if !xDone {
-			xCycle++
-		}
-
-		if !yDone {
-			yCycle++
-		}
-
-		if !zDone {
-			zCycle++
-		}
-
-		// apply gravity
-		for i, b1 := range bodies {
-			acc := Vec{}
-
-			for _, b2 := range bodies {
-				acc = Add(acc, gravity(b1.pos, b2.pos))
-			}
-
-			bodies[i].vel = Add(bodies[i].vel, acc)
-		}
+	sim, err := ParseFile("input.txt")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-		// apply velocity
+	period, periods, err := sim.Period(0)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-		for i, b := range bodies {
-			bodies[i].pos = Add(b.pos, b.vel)
-		}
+	fmt.Println(periods)
+	fmt.Println(period)
+}
 
-		if !xDone {
-			ok := true
+// export writes the trajectory of the first example for two of its
+// periods into dir, to check the period detection by eye.
+func export(dir string) error {
+	sim, err := ParseFile("input1.txt")
+	if err != nil {
+		return err
+	}
 
-			for i, _ := range bodies {
-				if ok && (bodies[i].vel.X != start[i].vel.X || bodies[i].pos.X != start[i].pos.X) {
-					ok = false
-				}
-			}
+	period, periods, err := sim.Period(0)
+	if err != nil {
+		return err
+	}
 
-			if ok {
-				xDone = true
-			}
+	maxPeriod := 0
+	for _, p := range periods {
+		if p > maxPeriod {
+			maxPeriod = p
 		}
+	}
 
-		if !yDone {
-			ok := true
-
-			for i, _ := range bodies {
-				if ok && (bodies[i].vel.Y != start[i].vel.Y || bodies[i].pos.Y != start[i].pos.Y) {
-					ok = false
-				}
-			}
+	csvFile, err := os.Create(filepath.Join(dir, "trajectory.csv"))
+	if err != nil {
+		return err
+	}
+	defer csvFile.Close()
 
-			if ok {
-				yDone = true
-			}
-		}
+	jsonFile, err := os.Create(filepath.Join(dir, "trajectory.jsonl"))
+	if err != nil {
+		return err
+	}
+	defer jsonFile.Close()
 
-		if !zDone {
-			ok := true
+	csvWriter := NewCSVWriter(csvFile, sim.Axes)
+	jsonWriter := NewJSONWriter(jsonFile)
+	states := []State{}
 
-			for i, _ := range bodies {
-				if ok && (bodies[i].vel.Z != start[i].vel.Z || bodies[i].pos.Z != start[i].pos.Z) {
-					ok = false
-				}
-			}
+	sim.Run(2*maxPeriod, 1, func(s State) {
+		csvWriter.Report(s)
+		jsonWriter.Report(s)
+		states = append(states, s)
+	})
 
-			if ok {
-				zDone = true
-			}
-		}
+	if err := csvWriter.Flush(); err != nil {
+		return err
+	}
 
-		if xDone && yDone && zDone {
-			break
-		}
+	if err := jsonWriter.Flush(); err != nil {
+		return err
 	}
 
-	result := lcm(xCycle, lcm(yCycle, zCycle))
+	plot, err := os.Create(filepath.Join(dir, "trajectory.svg"))
+	if err != nil {
+		return err
+	}
+	defer plot.Close()
 
-	fmt.Println(result)
-}
+	fmt.Println(periods, period)
 
-func lcm(a, b int) int {
-	return a * b / gcd(a, b)
+	return WritePlotSVG(plot, sim.Axes, states, periods)
 }
 
-func gcd(a, b int) int {
-	if a == 0 {
-		return b
-	}
+func main() {
+	out := flag.String("out", "", "directory to write the trajectory files to, nothing is written when empty")
 
-	return gcd(b%a, a)
-}
+	flag.Parse()
 
-func main() {
 	part1()
 	part2()
+
+	if *out == "" {
+		return
+	}
+
+	if err := export(*out); err != nil {
+		fmt.Println(err)
+	}
 }
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Simulation moves any number of bodies along any number of axes. Axes
+// don't affect each other, so each one can be simulated on its own.
+type Simulation struct {
+	Axes []string // names from the input, like x, y and z
+	Pos  [][]int  // body, axis
+	Vel  [][]int
+	Step int
+
+	// Gravity returns the pull on a body at a towards a body at b, the
+	// puzzle rule is gravityOnAxis.
+	Gravity func(a, b int) int
+
+	start [][]int
+}
+
+// State is a copy of the simulation at one step.
+type State struct {
+	Step int
+	Pos  [][]int
+	Vel  [][]int
+}
+
+func NewSimulation(axes []string, positions [][]int) (*Simulation, error) {
+	if len(positions) == 0 {
+		return nil, fmt.Errorf("Simulation needs at least one body")
+	}
+
+	s := &Simulation{Axes: axes, Gravity: gravityOnAxis}
+
+	for i, p := range positions {
+		if len(p) != len(axes) {
+			return nil, fmt.Errorf("Body %d has %d coordinates, expected %d", i+1, len(p), len(axes))
+		}
+
+		s.Pos = append(s.Pos, append([]int{}, p...))
+		s.Vel = append(s.Vel, make([]int, len(axes)))
+		s.start = append(s.start, append([]int{}, p...))
+	}
+
+	return s, nil
+}
+
+var coordinate = regexp.MustCompile(`^\s*([a-zA-Z]+)\s*=\s*(-?\d+)\s*$`)
+
+// Parse reads one body per line in the <x=1, y=2, z=3> format. Every line
+// must name the same axes in the same order.
+func Parse(r io.Reader) (*Simulation, error) {
+	axes := []string{}
+	positions := [][]int{}
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(text, "<") || !strings.HasSuffix(text, ">") {
+			return nil, fmt.Errorf("Line %d: expected <x=.., y=.., z=..>, got %q", line, text)
+		}
+
+		names := []string{}
+		pos := []int{}
+
+		for _, part := range strings.Split(text[1:len(text)-1], ",") {
+			match := coordinate.FindStringSubmatch(part)
+			if match == nil {
+				return nil, fmt.Errorf("Line %d: invalid coordinate %q", line, strings.TrimSpace(part))
+			}
+
+			value, err := strconv.Atoi(match[2])
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: %s", line, err)
+			}
+
+			names = append(names, match[1])
+			pos = append(pos, value)
+		}
+
+		if len(positions) == 0 {
+			axes = names
+		} else if strings.Join(names, ",") != strings.Join(axes, ",") {
+			return nil, fmt.Errorf("Line %d: axes %s don't match %s", line, strings.Join(names, ","), strings.Join(axes, ","))
+		}
+
+		positions = append(positions, pos)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewSimulation(axes, positions)
+}
+
+func ParseFile(filename string) (*Simulation, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+func (s *Simulation) stepAxis(axis int) {
+	for i := range s.Pos {
+		for j := range s.Pos {
+			s.Vel[i][axis] += s.Gravity(s.Pos[i][axis], s.Pos[j][axis])
+		}
+	}
+
+	for i := range s.Pos {
+		s.Pos[i][axis] += s.Vel[i][axis]
+	}
+}
+
+// Advance applies gravity and then velocity once.
+func (s *Simulation) Advance() {
+	for axis := range s.Axes {
+		s.stepAxis(axis)
+	}
+
+	s.Step++
+}
+
+// Run advances the given number of steps. report, when set, gets the state
+// before the first step and after every n-th step.
+func (s *Simulation) Run(steps int, every int, report func(State)) {
+	if report != nil {
+		report(s.State())
+	}
+
+	for i := 1; i <= steps; i++ {
+		s.Advance()
+
+		if report != nil && every > 0 && i%every == 0 {
+			report(s.State())
+		}
+	}
+}
+
+func (s *Simulation) State() State {
+	state := State{Step: s.Step}
+
+	for i := range s.Pos {
+		state.Pos = append(state.Pos, append([]int{}, s.Pos[i]...))
+		state.Vel = append(state.Vel, append([]int{}, s.Vel[i]...))
+	}
+
+	return state
+}
+
+// Energy is the sum of potential times kinetic energy of every body.
+func (s *Simulation) Energy() int {
+	total := 0
+
+	for i := range s.Pos {
+		potential := 0
+		kinetic := 0
+
+		for axis := range s.Axes {
+			potential += Abs(s.Pos[i][axis])
+			kinetic += Abs(s.Vel[i][axis])
+		}
+
+		total += potential * kinetic
+	}
+
+	return total
+}
+
+// AxisPeriod is the number of steps until the axis is back where the
+// simulation started. Every step can be undone, so the first repeated state
+// is the starting one. Gives up after limit steps, 0 means no limit.
+func (s *Simulation) AxisPeriod(axis int, limit int) (int, error) {
+	if axis < 0 || axis >= len(s.Axes) {
+		return 0, fmt.Errorf("Unknown axis %d", axis)
+	}
+
+	sim := &Simulation{Axes: s.Axes, Gravity: s.Gravity}
+
+	for i := range s.start {
+		sim.Pos = append(sim.Pos, append([]int{}, s.start[i]...))
+		sim.Vel = append(sim.Vel, make([]int, len(s.Axes)))
+	}
+
+	for steps := 1; limit == 0 || steps <= limit; steps++ {
+		sim.stepAxis(axis)
+
+		back := true
+
+		for i := range sim.Pos {
+			if sim.Pos[i][axis] != s.start[i][axis] || sim.Vel[i][axis] != 0 {
+				back = false
+				break
+			}
+		}
+
+		if back {
+			return steps, nil
+		}
+	}
+
+	return 0, fmt.Errorf("Axis %s did not repeat in %d steps", s.Axes[axis], limit)
+}
+
+// Period is the number of steps until every body is back at the start,
+// the lcm of the axis periods.
+func (s *Simulation) Period(limit int) (int, []int, error) {
+	result := 1
+	periods := []int{}
+
+	for axis := range s.Axes {
+		period, err := s.AxisPeriod(axis, limit)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		periods = append(periods, period)
+		result = lcm(result, period)
+	}
+
+	return result, periods, nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+const (
+	TileEmpty  = 0
+	TileWall   = 1
+	TileBlock  = 2
+	TilePaddle = 3
+	TileBall   = 4
+)
+
+type Stats struct {
+	Frames        int
+	Moves         int
+	BlocksCleared int
+	Score         int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("frames: %d, moves: %d, blocks cleared: %d, score: %d", s.Frames, s.Moves, s.BlocksCleared, s.Score)
+}
+
+type Game struct {
+	process  *Process
+	screen   *Screen
+	score    int
+	recorder *Recorder
+
+	ball         Point
+	previousBall Point
+	paddle       Point
+
+	initialBlocks int
+	stats         Stats
+}
+
+// Agent decides how to move the paddle: -1 left, 0 stay, 1 right.
+type Agent interface {
+	Move(g *Game) int
+}
+
+func NewGame(code []int) *Game {
+	return &Game{process: NewProcess(code, []int{}), screen: NewScreen()}
+}
+
+// Record captures every n-th frame into the recorder while playing.
+func (g *Game) Record(r *Recorder) {
+	g.recorder = r
+}
+
+func (g *Game) update() {
+	p := g.process
+
+	for len(p.output)-p.outputPointer >= 3 {
+		x := p.NextOutput()
+		y := p.NextOutput()
+		t := p.NextOutput()
+
+		if x == -1 && y == 0 {
+			g.score = t
+			continue
+		}
+
+		g.screen.Set(Point{X: x, Y: y}, t)
+
+		switch t {
+		case TileBall:
+			g.previousBall = g.ball
+			g.ball = Point{X: x, Y: y}
+
+			if g.stats.Frames == 0 {
+				g.previousBall = g.ball
+			}
+		case TilePaddle:
+			g.paddle = Point{X: x, Y: y}
+		}
+	}
+}
+
+func (g *Game) BlockCount() int {
+	return g.screen.Count(TileBlock)
+}
+
+func (g *Game) Render() {
+	print("\033[H\033[2J")
+
+	fmt.Println(Text(g.screen.Grid()))
+	fmt.Println(g.score)
+}
+
+// Play runs the game until all blocks are cleared or the program halts
+// (the ball was missed).
+func (g *Game) Play(agent Agent, show bool) (Stats, error) {
+	for {
+		if err := g.process.RunTilInputNeeded(); err != nil {
+			return g.stats, err
+		}
+
+		g.update()
+
+		blocks := g.BlockCount()
+
+		if g.stats.Frames == 0 {
+			g.initialBlocks = blocks
+		}
+
+		g.stats.Frames++
+		g.stats.BlocksCleared = g.initialBlocks - blocks
+		g.stats.Score = g.score
+
+		if show {
+			g.Render()
+		}
+
+		if g.recorder != nil {
+			g.recorder.Capture(g)
+		}
+
+		if blocks == 0 || g.process.halted {
+			return g.stats, nil
+		}
+
+		move := agent.Move(g)
+		if move != 0 {
+			g.stats.Moves++
+		}
+
+		g.process.AddInput(move)
+	}
+}
+
+func (g *Game) Won() bool {
+	return g.BlockCount() == 0
+}
+
+func direction(from, to int) int {
+	switch {
+	case to < from:
+		return -1
+	case to > from:
+		return 1
+	default:
+		return 0
+	}
+}
+
+//
+// Agents
+//
+
+// GreedyAgent keeps the paddle under the ball.
+type GreedyAgent struct{}
+
+func (a *GreedyAgent) Move(g *Game) int {
+	return direction(g.paddle.X, g.ball.X)
+}
+
+// PredictorAgent follows a falling ball's trajectory, bouncing off the walls,
+// to the row just above the paddle and waits there. Blocks are ignored, so the
+// prediction is corrected every frame. A rising ball can hit a block anytime,
+// so the paddle stays under it until it falls.
+type PredictorAgent struct{}
+
+func (a *PredictorAgent) Move(g *Game) int {
+	return direction(g.paddle.X, a.predict(g))
+}
+
+func (a *PredictorAgent) predict(g *Game) int {
+	pos := g.ball
+	vx := g.ball.X - g.previousBall.X
+	vy := g.ball.Y - g.previousBall.Y
+
+	if vy <= 0 {
+		return g.ball.X
+	}
+
+	min, max := g.screen.Bounds()
+
+	for steps := 0; steps < g.screen.Width()*g.screen.Height(); steps++ {
+		if pos.Y >= g.paddle.Y-1 {
+			return pos.X
+		}
+
+		if pos.X+vx <= min.X || pos.X+vx >= max.X {
+			vx = -vx
+		}
+
+		pos.X += vx
+		pos.Y += vy
+	}
+
+	return g.ball.X
+}
+
+// KeyboardAgent reads one line per frame: "a" or "h" moves left, "d" or "l"
+// moves right, anything else keeps the paddle in place.
+type KeyboardAgent struct {
+	reader *bufio.Reader
+}
+
+func NewKeyboardAgent(in io.Reader) *KeyboardAgent {
+	return &KeyboardAgent{reader: bufio.NewReader(in)}
+}
+
+func (a *KeyboardAgent) Move(g *Game) int {
+	fmt.Print("move [a/d]: ")
+
+	line, err := a.reader.ReadString('\n')
+	if err != nil || len(line) == 0 {
+		return 0
+	}
+
+	switch line[0] {
+	case 'a', 'h':
+		return -1
+	case 'd', 'l':
+		return 1
+	default:
+		return 0
+	}
+}
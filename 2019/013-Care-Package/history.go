@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+)
+
+//
+// Execution history for reverse debugging
+//
+// Every executed instruction pushes an undo record with the registers it is
+// about to change and the old value of every memory cell it writes. Records
+// live in a ring buffer, so only the last `capacity` instructions can be
+// undone.
+//
+
+type MemoryWrite struct {
+	Address  int
+	OldValue int
+	NewValue int
+}
+
+type UndoRecord struct {
+	position        int
+	relativeBase    int
+	inputPointer    int
+	outputLength    int
+	outputPointer   int
+	waitingForInput bool
+	halted          bool
+	writes          []MemoryWrite
+}
+
+type History struct {
+	records  []UndoRecord
+	start    int
+	size     int
+	executed int
+
+	stepping bool
+	stepped  bool
+	stalled  bool
+}
+
+func NewHistory(capacity int) *History {
+	return &History{records: make([]UndoRecord, capacity)}
+}
+
+func (p *Process) EnableHistory(capacity int) {
+	p.history = NewHistory(capacity)
+}
+
+// Len returns how many instructions can be stepped back.
+func (h *History) Len() int {
+	return h.size
+}
+
+// Executed returns how many instructions ran since history was enabled.
+func (h *History) Executed() int {
+	return h.executed
+}
+
+func (h *History) at(i int) *UndoRecord {
+	return &h.records[(h.start+i)%len(h.records)]
+}
+
+func (h *History) last() *UndoRecord {
+	if h.size == 0 {
+		return nil
+	}
+
+	return h.at(h.size - 1)
+}
+
+// begin is called before every instruction. In single step mode it refuses
+// to start a second instruction.
+func (h *History) begin(p *Process) bool {
+	if h.stepping {
+		if h.stepped {
+			return false
+		}
+
+		h.stepped = true
+	}
+
+	record := UndoRecord{
+		position:        p.position,
+		relativeBase:    p.relativeBase,
+		inputPointer:    p.inputPointer,
+		outputLength:    len(p.output),
+		outputPointer:   p.outputPointer,
+		waitingForInput: p.waitingForInput,
+		halted:          p.halted,
+	}
+
+	if h.size == len(h.records) {
+		h.start = (h.start + 1) % len(h.records)
+		h.size--
+	}
+
+	*h.at(h.size) = record
+	h.size++
+	h.executed++
+
+	return true
+}
+
+// stall drops the record of an input instruction that found no input, it
+// will be retried once input arrives.
+func (h *History) stall() {
+	h.pop()
+	h.stalled = true
+}
+
+func (h *History) pop() (UndoRecord, bool) {
+	if h.size == 0 {
+		return UndoRecord{}, false
+	}
+
+	record := *h.last()
+	h.size--
+	h.executed--
+
+	return record, true
+}
+
+func (p *Process) recordWrite(address int, value int) {
+	if p.history == nil || address < 0 || address >= len(p.memory) {
+		return
+	}
+
+	if last := p.history.last(); last != nil {
+		last.writes = append(last.writes, MemoryWrite{Address: address, OldValue: p.memory[address], NewValue: value})
+	}
+}
+
+//
+// Stepping
+//
+
+// Step executes exactly one instruction. It fails without changing anything
+// when the instruction needs input that is not there.
+func (p *Process) Step() error {
+	if p.history == nil {
+		return fmt.Errorf("History is not enabled")
+	}
+
+	if p.halted {
+		return fmt.Errorf("Process is halted")
+	}
+
+	p.history.stepping = true
+	p.history.stepped = false
+	p.history.stalled = false
+
+	err := p.RunTilInterupt()
+
+	p.history.stepping = false
+
+	if err == nil && p.history.stalled {
+		return fmt.Errorf("Waiting for input at %d", p.position)
+	}
+
+	return err
+}
+
+// StepBack undoes the last executed instruction.
+func (p *Process) StepBack() error {
+	if p.history == nil {
+		return fmt.Errorf("History is not enabled")
+	}
+
+	record, ok := p.history.pop()
+	if !ok {
+		return fmt.Errorf("No more history to step back")
+	}
+
+	for i := len(record.writes) - 1; i >= 0; i-- {
+		p.memory[record.writes[i].Address] = record.writes[i].OldValue
+	}
+
+	p.position = record.position
+	p.relativeBase = record.relativeBase
+	p.inputPointer = record.inputPointer
+	p.output = p.output[:record.outputLength]
+	p.outputPointer = record.outputPointer
+	p.waitingForInput = record.waitingForInput
+	p.halted = record.halted
+
+	return nil
+}
+
+// ReverseContinue steps back until the instruction that last wrote to address
+// is undone, leaving the process just before that write. Returns the number
+// of instructions stepped back.
+func (p *Process) ReverseContinue(address int) (int, error) {
+	if p.history == nil {
+		return 0, fmt.Errorf("History is not enabled")
+	}
+
+	// find the write first, on a miss the process is left untouched
+	steps := 0
+
+	for i := p.history.size - 1; steps == 0 && i >= 0; i-- {
+		for _, w := range p.history.at(i).writes {
+			if w.Address == address {
+				steps = p.history.size - i
+			}
+		}
+	}
+
+	if steps == 0 {
+		return 0, fmt.Errorf("No write to %d in the last %d instructions", address, p.history.size)
+	}
+
+	for i := 0; i < steps; i++ {
+		if err := p.StepBack(); err != nil {
+			return i, err
+		}
+	}
+
+	return steps, nil
+}
+
+//
+// Inspecting the past
+//
+
+type Snapshot struct {
+	Ago          int
+	Position     int
+	RelativeBase int
+	InputPointer int
+	OutputLength int
+	Changed      map[int]int
+
+	process *Process
+}
+
+// Ago reconstructs the process state from n instructions ago without
+// modifying the process.
+func (p *Process) Ago(n int) (*Snapshot, error) {
+	if p.history == nil {
+		return nil, fmt.Errorf("History is not enabled")
+	}
+
+	if n > p.history.size {
+		return nil, fmt.Errorf("Only %d instructions of history available", p.history.size)
+	}
+
+	snapshot := &Snapshot{
+		Ago:          n,
+		Position:     p.position,
+		RelativeBase: p.relativeBase,
+		InputPointer: p.inputPointer,
+		OutputLength: len(p.output),
+		Changed:      map[int]int{},
+		process:      p,
+	}
+
+	for i := 0; i < n; i++ {
+		record := p.history.at(p.history.size - 1 - i)
+
+		for j := len(record.writes) - 1; j >= 0; j-- {
+			snapshot.Changed[record.writes[j].Address] = record.writes[j].OldValue
+		}
+
+		snapshot.Position = record.position
+		snapshot.RelativeBase = record.relativeBase
+		snapshot.InputPointer = record.inputPointer
+		snapshot.OutputLength = record.outputLength
+	}
+
+	return snapshot, nil
+}
+
+func (s *Snapshot) Read(address int) (int, error) {
+	if v, ok := s.Changed[address]; ok {
+		return v, nil
+	}
+
+	return s.process.Read(address)
+}
+
+func (s *Snapshot) String() string {
+	return fmt.Sprintf("%d instructions ago: pc=%d rb=%d input=%d output=%d changed=%d cells", s.Ago, s.Position, s.RelativeBase, s.InputPointer, s.OutputLength, len(s.Changed))
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//
+// Memory tracing and diffing between runs
+//
+// A trace samples the low part of memory every time the program asks for
+// input (once per frame for the arcade) and once more when it halts. Two
+// traces can be diffed cell by cell, and a single trace can be split into
+// regions by how the cells behave over time.
+//
+
+type Patch map[int]int
+
+type Trace struct {
+	Name   string
+	Frames [][]int
+}
+
+// TraceRun plays code with the patch applied, the agent moves the paddle
+// whenever the program waits for input. Memory below limit is sampled.
+func TraceRun(name string, code []int, patch Patch, agent Agent, limit int, maxFrames int) (*Trace, error) {
+	g := NewGame(code)
+	p := g.process
+
+	if limit < 1 || limit > len(p.memory) {
+		return nil, fmt.Errorf("Limit %d out of range, memory has %d cells", limit, len(p.memory))
+	}
+
+	if maxFrames < 1 {
+		return nil, fmt.Errorf("Invalid frame count %d", maxFrames)
+	}
+
+	for address, value := range patch {
+		if address < 0 || address >= len(p.memory) {
+			return nil, fmt.Errorf("Patch address %d out of range", address)
+		}
+
+		p.memory[address] = value
+	}
+
+	trace := &Trace{Name: name}
+
+	for len(trace.Frames) < maxFrames {
+		if err := p.RunTilInputNeeded(); err != nil {
+			return trace, err
+		}
+
+		// only reads the output added since the last frame
+		g.update()
+		g.stats.Frames++
+
+		frame := make([]int, limit)
+		copy(frame, p.memory[:limit])
+		trace.Frames = append(trace.Frames, frame)
+
+		if p.halted {
+			break
+		}
+
+		p.AddInput(agent.Move(g))
+	}
+
+	return trace, nil
+}
+
+// Width is the number of memory cells sampled in every frame.
+func (t *Trace) Width() int {
+	if len(t.Frames) == 0 {
+		return 0
+	}
+
+	return len(t.Frames[0])
+}
+
+func (t *Trace) traced(address int) bool {
+	return address >= 0 && address < t.Width()
+}
+
+func (t *Trace) at(frame, address int) int {
+	if frame >= len(t.Frames) {
+		frame = len(t.Frames) - 1
+	}
+
+	return t.Frames[frame][address]
+}
+
+//
+// Diffing
+//
+
+type Divergence struct {
+	Address int
+	Frame   int
+	ValueA  int
+	ValueB  int
+}
+
+// Diff returns, for every cell that differs between the two traces, the
+// first frame in which it does. The shorter trace is padded with its last frame.
+func Diff(a, b *Trace) []Divergence {
+	result := []Divergence{}
+
+	frames := len(a.Frames)
+	if len(b.Frames) > frames {
+		frames = len(b.Frames)
+	}
+
+	limit := a.Width()
+	if b.Width() < limit {
+		limit = b.Width()
+	}
+
+	for address := 0; address < limit; address++ {
+		for frame := 0; frame < frames; frame++ {
+			va := a.at(frame, address)
+			vb := b.at(frame, address)
+
+			if va != vb {
+				result = append(result, Divergence{Address: address, Frame: frame, ValueA: va, ValueB: vb})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Frame < result[j].Frame
+	})
+
+	return result
+}
+
+func DiffReport(a, b *Trace, max int) string {
+	divergences := Diff(a, b)
+
+	lines := []string{fmt.Sprintf("%s (%d frames) vs %s (%d frames): %d cells diverge", a.Name, len(a.Frames), b.Name, len(b.Frames), len(divergences))}
+
+	for i, d := range divergences {
+		if i == max {
+			lines = append(lines, fmt.Sprintf("... %d more", len(divergences)-max))
+			break
+		}
+
+		lines = append(lines, fmt.Sprintf("frame %5d  mem[%4d]  %d != %d", d.Frame, d.Address, d.ValueA, d.ValueB))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+//
+// Watching and labeling cells
+//
+
+const (
+	LabelStatic      = "static"
+	LabelBuffer      = "buffer"
+	LabelCounterUp   = "counter-up"
+	LabelCounterDown = "counter-down"
+	LabelVolatile    = "volatile"
+	LabelState       = "state"
+)
+
+// minimal number of changing cells that form a buffer (e.g. the screen) and
+// the widest gap of unchanged cells allowed inside one
+const bufferLength = 16
+
+type CellStats struct {
+	Changes    int
+	Increasing bool
+	Decreasing bool
+}
+
+type Region struct {
+	From    int
+	To      int
+	Label   string
+	Changes int
+}
+
+func (t *Trace) Stats(address int) CellStats {
+	stats := CellStats{Increasing: true, Decreasing: true}
+
+	if !t.traced(address) {
+		return stats
+	}
+
+	for frame := 1; frame < len(t.Frames); frame++ {
+		prev := t.Frames[frame-1][address]
+		curr := t.Frames[frame][address]
+
+		if prev == curr {
+			continue
+		}
+
+		stats.Changes++
+
+		if curr < prev {
+			stats.Increasing = false
+		}
+
+		if curr > prev {
+			stats.Decreasing = false
+		}
+	}
+
+	return stats
+}
+
+func (t *Trace) label(address int) string {
+	stats := t.Stats(address)
+
+	switch {
+	case stats.Changes == 0:
+		return LabelStatic
+	case stats.Changes*2 > len(t.Frames):
+		return LabelVolatile
+	case stats.Increasing:
+		return LabelCounterUp
+	case stats.Decreasing:
+		return LabelCounterDown
+	default:
+		return LabelState
+	}
+}
+
+// Regions lists the parts of memory that change during the run. Clusters of
+// many rarely changing cells become one buffer, the remaining cells are
+// grouped with neighbours of the same label. Static memory is left out.
+func (t *Trace) Regions() []Region {
+	changing := []int{}
+	labels := map[int]string{}
+
+	for address := 0; address < t.Width(); address++ {
+		label := t.label(address)
+		if label == LabelStatic {
+			continue
+		}
+
+		changing = append(changing, address)
+		labels[address] = label
+	}
+
+	regions := []Region{}
+
+	for i := 0; i < len(changing); {
+		// grow a cluster of non volatile cells with small gaps
+		j := i
+		for labels[changing[i]] != LabelVolatile && j+1 < len(changing) && labels[changing[j+1]] != LabelVolatile && changing[j+1]-changing[j] <= bufferLength {
+			j++
+		}
+
+		if j-i+1 >= bufferLength {
+			region := Region{From: changing[i], To: changing[j] + 1, Label: LabelBuffer}
+			for k := i; k <= j; k++ {
+				region.Changes += t.Stats(changing[k]).Changes
+			}
+
+			regions = append(regions, region)
+			i = j + 1
+			continue
+		}
+
+		address := changing[i]
+		changes := t.Stats(address).Changes
+
+		if n := len(regions); n > 0 && regions[n-1].Label == labels[address] && regions[n-1].To == address {
+			regions[n-1].To++
+			regions[n-1].Changes += changes
+		} else {
+			regions = append(regions, Region{From: address, To: address + 1, Label: labels[address], Changes: changes})
+		}
+
+		i++
+	}
+
+	return regions
+}
+
+func (t *Trace) RegionReport() string {
+	lines := []string{}
+
+	for _, r := range t.Regions() {
+		lines = append(lines, fmt.Sprintf("%5d..%-5d %-12s %d changes", r.From, r.To-1, r.Label, r.Changes))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Watch lists every change of the given cells over the run.
+func (t *Trace) Watch(addresses ...int) string {
+	lines := []string{}
+
+	for _, address := range addresses {
+		if !t.traced(address) {
+			lines = append(lines, fmt.Sprintf("mem[%d] was not traced", address))
+			continue
+		}
+
+		changes := []string{fmt.Sprintf("%d", t.Frames[0][address])}
+
+		for frame := 1; frame < len(t.Frames); frame++ {
+			if t.Frames[frame][address] != t.Frames[frame-1][address] {
+				changes = append(changes, fmt.Sprintf("%d@%d", t.Frames[frame][address], frame))
+			}
+		}
+
+		lines = append(lines, fmt.Sprintf("mem[%d] (%s): %s", address, t.label(address), strings.Join(changes, " ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
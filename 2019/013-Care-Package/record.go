@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"strings"
+)
+
+//
+// Recording frames for later review
+//
+// Frames are exported as an animated GIF (one colored square per tile) or as
+// an asciinema v2 cast file that replays the text rendering.
+//
+
+type RecordedFrame struct {
+	Grid  [][]int
+	Min   Point
+	Score int
+}
+
+type Recorder struct {
+	every  int
+	seen   int
+	frames []RecordedFrame
+}
+
+// NewRecorder keeps every n-th frame. The last frame of a game is always kept.
+func NewRecorder(every int) *Recorder {
+	if every < 1 {
+		every = 1
+	}
+
+	return &Recorder{every: every}
+}
+
+func (r *Recorder) Frames() []RecordedFrame {
+	return r.frames
+}
+
+func (r *Recorder) Capture(g *Game) {
+	r.seen++
+
+	over := g.BlockCount() == 0 || g.process.halted
+
+	if (r.seen-1)%r.every != 0 && !over {
+		return
+	}
+
+	min, _ := g.screen.Bounds()
+
+	r.frames = append(r.frames, RecordedFrame{Grid: g.screen.Grid(), Min: min, Score: g.score})
+}
+
+// bounds of all recorded frames, the screen may have grown during the game
+func (r *Recorder) bounds() (Point, Point) {
+	min := r.frames[0].Min
+	max := r.frames[0].Min
+
+	for _, f := range r.frames {
+		if f.Min.X < min.X {
+			min.X = f.Min.X
+		}
+		if f.Min.Y < min.Y {
+			min.Y = f.Min.Y
+		}
+		if len(f.Grid) > 0 && f.Min.X+len(f.Grid[0])-1 > max.X {
+			max.X = f.Min.X + len(f.Grid[0]) - 1
+		}
+		if f.Min.Y+len(f.Grid)-1 > max.Y {
+			max.Y = f.Min.Y + len(f.Grid) - 1
+		}
+	}
+
+	return min, max
+}
+
+var tileColors = map[int]color.Color{
+	TileEmpty:  color.RGBA{0x10, 0x10, 0x18, 0xff},
+	TileWall:   color.RGBA{0x80, 0x80, 0x80, 0xff},
+	TileBlock:  color.RGBA{0x30, 0x70, 0xd0, 0xff},
+	TilePaddle: color.RGBA{0xf0, 0xf0, 0xf0, 0xff},
+	TileBall:   color.RGBA{0xe0, 0x30, 0x30, 0xff},
+}
+
+var unknownTileColor = color.RGBA{0xe0, 0x30, 0xe0, 0xff}
+
+// WriteGIF draws each tile as a scale x scale square. delay is in 100ths of
+// a second.
+func (r *Recorder) WriteGIF(w io.Writer, scale int, delay int) error {
+	if len(r.frames) == 0 {
+		return fmt.Errorf("No frames recorded")
+	}
+
+	palette := color.Palette{}
+	index := map[int]uint8{}
+
+	for tile := TileEmpty; tile <= TileBall; tile++ {
+		index[tile] = uint8(len(palette))
+		palette = append(palette, tileColors[tile])
+	}
+
+	unknown := uint8(len(palette))
+	palette = append(palette, unknownTileColor)
+
+	min, max := r.bounds()
+	width := (max.X - min.X + 1) * scale
+	height := (max.Y - min.Y + 1) * scale
+
+	animation := &gif.GIF{}
+
+	for _, f := range r.frames {
+		img := image.NewPaletted(image.Rect(0, 0, width, height), palette)
+
+		for y, row := range f.Grid {
+			for x, tile := range row {
+				c, ok := index[tile]
+				if !ok {
+					c = unknown
+				}
+
+				left := (f.Min.X - min.X + x) * scale
+				top := (f.Min.Y - min.Y + y) * scale
+
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						img.SetColorIndex(left+dx, top+dy, c)
+					}
+				}
+			}
+		}
+
+		animation.Image = append(animation.Image, img)
+		animation.Delay = append(animation.Delay, delay)
+	}
+
+	return gif.EncodeAll(w, animation)
+}
+
+// WriteCast writes an asciinema v2 cast with one frame every interval
+// seconds.
+func (r *Recorder) WriteCast(w io.Writer, interval float64) error {
+	if len(r.frames) == 0 {
+		return fmt.Errorf("No frames recorded")
+	}
+
+	min, max := r.bounds()
+
+	header, err := json.Marshal(map[string]interface{}{
+		"version": 2,
+		"width":   max.X - min.X + 1,
+		"height":  max.Y - min.Y + 2,
+		"title":   "Care Package",
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "%s\n", header); err != nil {
+		return err
+	}
+
+	for i, f := range r.frames {
+		text := strings.Replace(Text(f.Grid), "\n", "\r\n", -1)
+		data := fmt.Sprintf("\033[H\033[2J%s\r\n%d", text, f.Score)
+
+		event, err := json.Marshal([]interface{}{float64(i) * interval, "o", data})
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "%s\n", event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
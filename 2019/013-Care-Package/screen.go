@@ -0,0 +1,124 @@
+package main
+
+import (
+	"strings"
+)
+
+// Screen holds the tiles drawn by the arcade program. It grows to fit every
+// tile that was ever drawn, in any direction.
+type Screen struct {
+	tiles map[Point]int
+	min   Point
+	max   Point
+}
+
+func NewScreen() *Screen {
+	return &Screen{tiles: map[Point]int{}}
+}
+
+func (s *Screen) Set(pos Point, tile int) {
+	if len(s.tiles) == 0 {
+		s.min = pos
+		s.max = pos
+	}
+
+	if pos.X < s.min.X {
+		s.min.X = pos.X
+	}
+	if pos.Y < s.min.Y {
+		s.min.Y = pos.Y
+	}
+	if pos.X > s.max.X {
+		s.max.X = pos.X
+	}
+	if pos.Y > s.max.Y {
+		s.max.Y = pos.Y
+	}
+
+	s.tiles[pos] = tile
+}
+
+func (s *Screen) At(pos Point) int {
+	return s.tiles[pos]
+}
+
+// Bounds returns the top left and the bottom right corner (inclusive).
+func (s *Screen) Bounds() (Point, Point) {
+	return s.min, s.max
+}
+
+func (s *Screen) Width() int {
+	if len(s.tiles) == 0 {
+		return 0
+	}
+
+	return s.max.X - s.min.X + 1
+}
+
+func (s *Screen) Height() int {
+	if len(s.tiles) == 0 {
+		return 0
+	}
+
+	return s.max.Y - s.min.Y + 1
+}
+
+func (s *Screen) Count(tile int) int {
+	count := 0
+
+	for _, t := range s.tiles {
+		if t == tile {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Grid copies the visible area into rows, empty where nothing was drawn.
+func (s *Screen) Grid() [][]int {
+	grid := make([][]int, s.Height())
+
+	for y := range grid {
+		grid[y] = make([]int, s.Width())
+
+		for x := range grid[y] {
+			grid[y][x] = s.tiles[Point{X: s.min.X + x, Y: s.min.Y + y}]
+		}
+	}
+
+	return grid
+}
+
+var sprites = map[int]string{
+	TileEmpty:  " ",
+	TileWall:   "█",
+	TileBlock:  "▒",
+	TilePaddle: "▔",
+	TileBall:   "●",
+}
+
+func sprite(tile int) string {
+	if s, ok := sprites[tile]; ok {
+		return s
+	}
+
+	return "?"
+}
+
+// Text renders the grid with sprites, one line per row.
+func Text(grid [][]int) string {
+	lines := []string{}
+
+	for _, row := range grid {
+		line := ""
+
+		for _, tile := range row {
+			line += sprite(tile)
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
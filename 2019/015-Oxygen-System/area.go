@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/shiroyasha/advent-of-code-2017/2019/flood"
+)
+
+const (
+	TileWall   = '#'
+	TileOpen   = '.'
+	TileStart  = 'S'
+	TileOxygen = 'O'
+
+	// the droid starts on the oxygen system
+	TileStartOxygen = '@'
+)
+
+// Area is the part of the ship discovered by the droid. Positions missing
+// from Tiles were never seen.
+type Area struct {
+	Tiles     map[Pos]byte
+	Start     Pos
+	Oxygen    Pos
+	HasOxygen bool
+}
+
+func NewArea() *Area {
+	return &Area{Tiles: map[Pos]byte{}}
+}
+
+func (a *Area) Open(pos Pos) bool {
+	t, ok := a.Tiles[pos]
+
+	return ok && t != TileWall
+}
+
+func (a *Area) Bounds() (Pos, Pos) {
+	first := true
+	min, max := Pos{}, Pos{}
+
+	for pos := range a.Tiles {
+		if first {
+			min, max = pos, pos
+			first = false
+		}
+
+		if pos.X < min.X {
+			min.X = pos.X
+		}
+		if pos.Y < min.Y {
+			min.Y = pos.Y
+		}
+		if pos.X > max.X {
+			max.X = pos.X
+		}
+		if pos.Y > max.Y {
+			max.Y = pos.Y
+		}
+	}
+
+	return min, max
+}
+
+// String draws the area, unknown tiles are blank.
+func (a *Area) String() string {
+	min, max := a.Bounds()
+	lines := []string{}
+
+	for y := min.Y; y <= max.Y; y++ {
+		line := ""
+
+		for x := min.X; x <= max.X; x++ {
+			pos := Pos{X: x, Y: y}
+
+			switch {
+			case pos == a.Start && a.HasOxygen && pos == a.Oxygen:
+				line += string(TileStartOxygen)
+			case pos == a.Start:
+				line += string(TileStart)
+			case a.HasOxygen && pos == a.Oxygen:
+				line += string(TileOxygen)
+			case a.Tiles[pos] == 0:
+				line += " "
+			default:
+				line += string(a.Tiles[pos])
+			}
+		}
+
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (a *Area) Save(w io.Writer) error {
+	_, err := fmt.Fprintln(w, a.String())
+
+	return err
+}
+
+func (a *Area) SaveFile(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return a.Save(file)
+}
+
+// LoadArea reads a saved map. Positions are relative to the start tile.
+func LoadArea(r io.Reader) (*Area, error) {
+	area := NewArea()
+	start := Pos{}
+	hasStart := false
+
+	scanner := bufio.NewScanner(r)
+
+	for y := 0; scanner.Scan(); y++ {
+		for x, c := range scanner.Text() {
+			pos := Pos{X: x, Y: y}
+
+			switch c {
+			case ' ':
+				continue
+			case TileWall, TileOpen:
+				area.Tiles[pos] = byte(c)
+			case TileStart, TileOxygen, TileStartOxygen:
+				area.Tiles[pos] = TileOpen
+
+				if c == TileStart || c == TileStartOxygen {
+					if hasStart {
+						return nil, fmt.Errorf("Line %d: second start tile", y+1)
+					}
+
+					start = pos
+					hasStart = true
+				}
+
+				if c == TileOxygen || c == TileStartOxygen {
+					if area.HasOxygen {
+						return nil, fmt.Errorf("Line %d: second oxygen system", y+1)
+					}
+
+					area.Oxygen = pos
+					area.HasOxygen = true
+				}
+			default:
+				return nil, fmt.Errorf("Line %d: unknown tile %q", y+1, c)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !hasStart {
+		return nil, fmt.Errorf("Map has no start tile")
+	}
+
+	tiles := map[Pos]byte{}
+
+	for pos, t := range area.Tiles {
+		pos.Substract(start)
+		tiles[pos] = t
+	}
+
+	area.Tiles = tiles
+	area.Oxygen.Substract(start)
+
+	return area, nil
+}
+
+func LoadAreaFile(filename string) (*Area, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadArea(file)
+}
+
+// Distances walks the open tiles breadth first.
+func (a *Area) Distances(from Pos) map[Pos]int {
+	distances := map[Pos]int{from: 0}
+	queue := []Pos{from}
+
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+
+		for _, m := range movements {
+			next := pos
+			next.Add(m)
+
+			if _, seen := distances[next]; seen || !a.Open(next) {
+				continue
+			}
+
+			distances[next] = distances[pos] + 1
+			queue = append(queue, next)
+		}
+	}
+
+	return distances
+}
+
+func (a *Area) ShortestPath(from, to Pos) (int, error) {
+	distance, ok := a.Distances(from)[to]
+	if !ok {
+		return 0, fmt.Errorf("No path from %v to %v", from, to)
+	}
+
+	return distance, nil
+}
+
+// Oxygen spreads from the oxygen system into every open tile.
+func (a *Area) Oxygenate() (*flood.Result, error) {
+	if !a.HasOxygen {
+		return nil, fmt.Errorf("Oxygen system was not found")
+	}
+
+	f := &flood.Flood{
+		Sources: []flood.Point{flood.Point(a.Oxygen)},
+		Wall: func(p flood.Point) bool {
+			return !a.Open(Pos(p))
+		},
+	}
+
+	return f.Run()
+}
+
+// FillTime is the number of minutes until oxygen reaches every open tile.
+func (a *Area) FillTime() (int, error) {
+	result, err := a.Oxygenate()
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Time, nil
+}
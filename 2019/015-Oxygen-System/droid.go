@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+)
+
+const (
+	StatusWall   = 0
+	StatusMoved  = 1
+	StatusOxygen = 2
+)
+
+// RepairDroid maps the area by always walking to the closest known tile that
+// still has unexplored neighbours.
+type RepairDroid struct {
+	process *Process
+	pos     Pos
+	area    *Area
+}
+
+func NewRepairDroid(code []int) *RepairDroid {
+	area := NewArea()
+	area.Tiles[Pos{}] = TileOpen
+
+	return &RepairDroid{process: NewProcess(code, []int{}), area: area}
+}
+
+// Step sends one movement command (1 north, 2 south, 3 west, 4 east) and
+// records what the droid found.
+func (d *RepairDroid) Step(dir int) (int, error) {
+	p := d.process
+	outputs := len(p.output)
+
+	p.AddInput(dir)
+
+	for len(p.output) == outputs {
+		if p.halted {
+			return 0, fmt.Errorf("Droid program halted")
+		}
+
+		position := p.position
+
+		if err := p.RunTilInterupt(); err != nil {
+			return 0, err
+		}
+
+		if p.position == position && len(p.output) == outputs {
+			return 0, fmt.Errorf("Droid is waiting for more input at %d", p.position)
+		}
+	}
+
+	status := p.NextOutput()
+	target := move(d.pos, dir)
+
+	switch status {
+	case StatusWall:
+		d.area.Tiles[target] = TileWall
+	case StatusMoved:
+		d.area.Tiles[target] = TileOpen
+		d.pos = target
+	case StatusOxygen:
+		d.area.Tiles[target] = TileOpen
+		d.area.Oxygen = target
+		d.area.HasOxygen = true
+		d.pos = target
+	default:
+		return 0, fmt.Errorf("Unknown status %d", status)
+	}
+
+	return status, nil
+}
+
+// frontier finds the path to the closest open tile with an unknown
+// neighbour. ok is false when the whole area is mapped.
+func (d *RepairDroid) frontier() ([]int, bool) {
+	paths := map[Pos][]int{d.pos: []int{}}
+	queue := []Pos{d.pos}
+
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+
+		for dir := 1; dir <= 4; dir++ {
+			if _, known := d.area.Tiles[move(pos, dir)]; !known {
+				return paths[pos], true
+			}
+		}
+
+		for dir := 1; dir <= 4; dir++ {
+			next := move(pos, dir)
+
+			if _, seen := paths[next]; seen || !d.area.Open(next) {
+				continue
+			}
+
+			path := append(append([]int{}, paths[pos]...), dir)
+			paths[next] = path
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, false
+}
+
+// Explore maps the whole reachable area and returns it.
+func (d *RepairDroid) Explore() (*Area, error) {
+	for {
+		path, ok := d.frontier()
+		if !ok {
+			return d.area, nil
+		}
+
+		for _, dir := range path {
+			status, err := d.Step(dir)
+			if err != nil {
+				return nil, err
+			}
+
+			if status == StatusWall {
+				return nil, fmt.Errorf("Droid hit a wall at %v on a known path", move(d.pos, dir))
+			}
+		}
+
+		for dir := 1; dir <= 4; dir++ {
+			if _, known := d.area.Tiles[move(d.pos, dir)]; known {
+				continue
+			}
+
+			status, err := d.Step(dir)
+			if err != nil {
+				return nil, err
+			}
+
+			if status != StatusWall {
+				if _, err := d.Step(oposites[dir-1]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+}
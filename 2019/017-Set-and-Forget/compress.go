@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+//
+// Compressing a route into a main routine and movement functions
+//
+// A route is a list of moves like "L,10". The solver tries every way of
+// cutting the route into calls of at most Functions movement functions, so
+// no valid assignment is missed.
+//
+
+type CompressOptions struct {
+	Functions int // number of movement functions (A, B, C, ...)
+	MaxLength int // characters allowed in the main routine and each function
+
+	// Score ranks the solutions, the lowest wins. Defaults to the total
+	// length of the program.
+	Score func(c *Compression) int
+}
+
+func DefaultCompressOptions() CompressOptions {
+	return CompressOptions{Functions: 3, MaxLength: 20}
+}
+
+type Compression struct {
+	Main      []int      // indexes into Functions
+	Functions [][]string // moves of every function
+}
+
+func (c *Compression) MainRoutine() string {
+	calls := []string{}
+
+	for _, f := range c.Main {
+		calls = append(calls, string(rune('A'+f)))
+	}
+
+	return strings.Join(calls, ",")
+}
+
+// UnusedFunction is sent for movement functions the main routine never
+// calls, the robot rejects an empty definition.
+const UnusedFunction = "L"
+
+// Function returns the i-th movement function, UnusedFunction when the
+// solution needs fewer functions.
+func (c *Compression) Function(i int) string {
+	if i >= len(c.Functions) {
+		return UnusedFunction
+	}
+
+	return strings.Join(c.Functions[i], ",")
+}
+
+// Moves expands the main routine back into the route.
+func (c *Compression) Moves() []string {
+	result := []string{}
+
+	for _, f := range c.Main {
+		result = append(result, c.Functions[f]...)
+	}
+
+	return result
+}
+
+func (c *Compression) String() string {
+	lines := []string{"Main: " + c.MainRoutine()}
+
+	for i := range c.Functions {
+		lines = append(lines, fmt.Sprintf("%c: %s", 'A'+i, c.Function(i)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ProgramLength is the number of characters sent to the robot, newlines
+// included.
+func ProgramLength(c *Compression) int {
+	result := len(c.MainRoutine()) + 1
+
+	for i := range c.Functions {
+		result += len(c.Function(i)) + 1
+	}
+
+	return result
+}
+
+func checkOptions(opts CompressOptions) error {
+	if opts.Functions < 1 || opts.Functions > 26 {
+		return fmt.Errorf("Invalid number of functions %d", opts.Functions)
+	}
+
+	if opts.MaxLength < 1 {
+		return fmt.Errorf("Invalid length limit %d", opts.MaxLength)
+	}
+
+	return nil
+}
+
+// CompressAll lists every valid compression of the route. Functions are
+// numbered in the order of their first call, so every assignment is listed
+// once.
+func CompressAll(moves []string, opts CompressOptions) ([]*Compression, error) {
+	if err := checkOptions(opts); err != nil {
+		return nil, err
+	}
+
+	if len(moves) == 0 {
+		return nil, fmt.Errorf("Route is empty")
+	}
+
+	result := []*Compression{}
+
+	var solve func(rest []string, c *Compression)
+
+	solve = func(rest []string, c *Compression) {
+		if len(rest) == 0 {
+			result = append(result, c.copy())
+			return
+		}
+
+		// the next call adds a letter and a comma to the main routine
+		if 2*len(c.Main)+1 > opts.MaxLength {
+			return
+		}
+
+		for i, f := range c.Functions {
+			if hasPrefix(rest, f) {
+				c.Main = append(c.Main, i)
+				solve(rest[len(f):], c)
+				c.Main = c.Main[:len(c.Main)-1]
+			}
+		}
+
+		if len(c.Functions) == opts.Functions {
+			return
+		}
+
+		for n := 1; n <= len(rest); n++ {
+			if len(strings.Join(rest[:n], ",")) > opts.MaxLength {
+				break
+			}
+
+			c.Functions = append(c.Functions, rest[:n])
+			c.Main = append(c.Main, len(c.Functions)-1)
+
+			solve(rest[n:], c)
+
+			c.Main = c.Main[:len(c.Main)-1]
+			c.Functions = c.Functions[:len(c.Functions)-1]
+		}
+	}
+
+	solve(moves, &Compression{})
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("Route of %d moves does not fit into %d functions of at most %d characters", len(moves), opts.Functions, opts.MaxLength)
+	}
+
+	return result, nil
+}
+
+// Compress returns the best scoring compression of the route.
+func Compress(moves []string, opts CompressOptions) (*Compression, error) {
+	all, err := CompressAll(moves, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	score := opts.Score
+	if score == nil {
+		score = ProgramLength
+	}
+
+	best := all[0]
+
+	for _, c := range all[1:] {
+		if score(c) < score(best) {
+			best = c
+		}
+	}
+
+	return best, nil
+}
+
+func (c *Compression) copy() *Compression {
+	result := &Compression{Main: append([]int{}, c.Main...)}
+
+	for _, f := range c.Functions {
+		result.Functions = append(result.Functions, append([]string{}, f...))
+	}
+
+	return result
+}
+
+func hasPrefix(moves, prefix []string) bool {
+	if len(prefix) > len(moves) {
+		return false
+	}
+
+	for i := range prefix {
+		if moves[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
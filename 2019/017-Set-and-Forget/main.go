@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"strings"
 )
 
 const debug = false
@@ -554,89 +553,14 @@ func (p *Pos) Substract(p2 Pos) {
 // func fillWithOxygen(queue []Pos) int {
 // }
 
-func getMap() [][]byte {
+// getMap returns the camera output
+func getMap() string {
 	code := []int{1, 330, 331, 332, 109, 6690, 1102, 1, 1182, 16, 1102, 1, 1505, 24, 102, 1, 0, 570, 1006, 570, 36, 1002, 571, 1, 0, 1001, 570, -1, 570, 1001, 24, 1, 24, 1106, 0, 18, 1008, 571, 0, 571, 1001, 16, 1, 16, 1008, 16, 1505, 570, 1006, 570, 14, 21102, 58, 1, 0, 1105, 1, 786, 1006, 332, 62, 99, 21101, 333, 0, 1, 21102, 73, 1, 0, 1105, 1, 579, 1102, 0, 1, 572, 1101, 0, 0, 573, 3, 574, 101, 1, 573, 573, 1007, 574, 65, 570, 1005, 570, 151, 107, 67, 574, 570, 1005, 570, 151, 1001, 574, -64, 574, 1002, 574, -1, 574, 1001, 572, 1, 572, 1007, 572, 11, 570, 1006, 570, 165, 101, 1182, 572, 127, 1002, 574, 1, 0, 3, 574, 101, 1, 573, 573, 1008, 574, 10, 570, 1005, 570, 189, 1008, 574, 44, 570, 1006, 570, 158, 1106, 0, 81, 21101, 340, 0, 1, 1106, 0, 177, 21102, 1, 477, 1, 1106, 0, 177, 21101, 0, 514, 1, 21102, 1, 176, 0, 1106, 0, 579, 99, 21101, 0, 184, 0, 1105, 1, 579, 4, 574, 104, 10, 99, 1007, 573, 22, 570, 1006, 570, 165, 1001, 572, 0, 1182, 21101, 375, 0, 1, 21102, 211, 1, 0, 1106, 0, 579, 21101, 1182, 11, 1, 21101, 222, 0, 0, 1105, 1, 979, 21101, 388, 0, 1, 21101, 0, 233, 0, 1105, 1, 579, 21101, 1182, 22, 1, 21102, 1, 244, 0, 1105, 1, 979, 21101, 401, 0, 1, 21101, 255, 0, 0, 1105, 1, 579, 21101, 1182, 33, 1, 21101, 0, 266, 0, 1105, 1, 979, 21101, 0, 414, 1, 21101, 277, 0, 0, 1106, 0, 579, 3, 575, 1008, 575, 89, 570, 1008, 575, 121, 575, 1, 575, 570, 575, 3, 574, 1008, 574, 10, 570, 1006, 570, 291, 104, 10, 21101, 1182, 0, 1, 21102, 1, 313, 0, 1106, 0, 622, 1005, 575, 327, 1101, 0, 1, 575, 21101, 327, 0, 0, 1106, 0, 786, 4, 438, 99, 0, 1, 1, 6, 77, 97, 105, 110, 58, 10, 33, 10, 69, 120, 112, 101, 99, 116, 101, 100, 32, 102, 117, 110, 99, 116, 105, 111, 110, 32, 110, 97, 109, 101, 32, 98, 117, 116, 32, 103, 111, 116, 58, 32, 0, 12, 70, 117, 110, 99, 116, 105, 111, 110, 32, 65, 58, 10, 12, 70, 117, 110, 99, 116, 105, 111, 110, 32, 66, 58, 10, 12, 70, 117, 110, 99, 116, 105, 111, 110, 32, 67, 58, 10, 23, 67, 111, 110, 116, 105, 110, 117, 111, 117, 115, 32, 118, 105, 100, 101, 111, 32, 102, 101, 101, 100, 63, 10, 0, 37, 10, 69, 120, 112, 101, 99, 116, 101, 100, 32, 82, 44, 32, 76, 44, 32, 111, 114, 32, 100, 105, 115, 116, 97, 110, 99, 101, 32, 98, 117, 116, 32, 103, 111, 116, 58, 32, 36, 10, 69, 120, 112, 101, 99, 116, 101, 100, 32, 99, 111, 109, 109, 97, 32, 111, 114, 32, 110, 101, 119, 108, 105, 110, 101, 32, 98, 117, 116, 32, 103, 111, 116, 58, 32, 43, 10, 68, 101, 102, 105, 110, 105, 116, 105, 111, 110, 115, 32, 109, 97, 121, 32, 98, 101, 32, 97, 116, 32, 109, 111, 115, 116, 32, 50, 48, 32, 99, 104, 97, 114, 97, 99, 116, 101, 114, 115, 33, 10, 94, 62, 118, 60, 0, 1, 0, -1, -1, 0, 1, 0, 0, 0, 0, 0, 0, 1, 84, 18, 0, 109, 4, 2101, 0, -3, 587, 20102, 1, 0, -1, 22101, 1, -3, -3, 21102, 1, 0, -2, 2208, -2, -1, 570, 1005, 570, 617, 2201, -3, -2, 609, 4, 0, 21201, -2, 1, -2, 1106, 0, 597, 109, -4, 2106, 0, 0, 109, 5, 2102, 1, -4, 629, 21001, 0, 0, -2, 22101, 1, -4, -4, 21102, 1, 0, -3, 2208, -3, -2, 570, 1005, 570, 781, 2201, -4, -3, 652, 21001, 0, 0, -1, 1208, -1, -4, 570, 1005, 570, 709, 1208, -1, -5, 570, 1005, 570, 734, 1207, -1, 0, 570, 1005, 570, 759, 1206, -1, 774, 1001, 578, 562, 684, 1, 0, 576, 576, 1001, 578, 566, 692, 1, 0, 577, 577, 21101, 702, 0, 0, 1106, 0, 786, 21201, -1, -1, -1, 1106, 0, 676, 1001, 578, 1, 578, 1008, 578, 4, 570, 1006, 570, 724, 1001, 578, -4, 578, 21101, 0, 731, 0, 1106, 0, 786, 1105, 1, 774, 1001, 578, -1, 578, 1008, 578, -1, 570, 1006, 570, 749, 1001, 578, 4, 578, 21102, 756, 1, 0, 1106, 0, 786, 1106, 0, 774, 21202, -1, -11, 1, 22101, 1182, 1, 1, 21102, 1, 774, 0, 1106, 0, 622, 21201, -3, 1, -3, 1105, 1, 640, 109, -5, 2105, 1, 0, 109, 7, 1005, 575, 802, 20101, 0, 576, -6, 21002, 577, 1, -5, 1106, 0, 814, 21102, 0, 1, -1, 21102, 0, 1, -5, 21102, 1, 0, -6, 20208, -6, 576, -2, 208, -5, 577, 570, 22002, 570, -2, -2, 21202, -5, 85, -3, 22201, -6, -3, -3, 22101, 1505, -3, -3, 1201, -3, 0, 843, 1005, 0, 863, 21202, -2, 42, -4, 22101, 46, -4, -4, 1206, -2, 924, 21101, 0, 1, -1, 1105, 1, 924, 1205, -2, 873, 21101, 0, 35, -4, 1105, 1, 924, 2101, 0, -3, 878, 1008, 0, 1, 570, 1006, 570, 916, 1001, 374, 1, 374, 2102, 1, -3, 895, 1102, 1, 2, 0, 2101, 0, -3, 902, 1001, 438, 0, 438, 2202, -6, -5, 570, 1, 570, 374, 570, 1, 570, 438, 438, 1001, 578, 558, 922, 20101, 0, 0, -4, 1006, 575, 959, 204, -4, 22101, 1, -6, -6, 1208, -6, 85, 570, 1006, 570, 814, 104, 10, 22101, 1, -5, -5, 1208, -5, 61, 570, 1006, 570, 810, 104, 10, 1206, -1, 974, 99, 1206, -1, 974, 1101, 0, 1, 575, 21102, 973, 1, 0, 1105, 1, 786, 99, 109, -7, 2106, 0, 0, 109, 6, 21101, 0, 0, -4, 21102, 0, 1, -3, 203, -2, 22101, 1, -3, -3, 21208, -2, 82, -1, 1205, -1, 1030, 21208, -2, 76, -1, 1205, -1, 1037, 21207, -2, 48, -1, 1205, -1, 1124, 22107, 57, -2, -1, 1205, -1, 1124, 21201, -2, -48, -2, 1106, 0, 1041, 21101, 0, -4, -2, 1105, 1, 1041, 21101, 0, -5, -2, 21201, -4, 1, -4, 21207, -4, 11, -1, 1206, -1, 1138, 2201, -5, -4, 1059, 2101, 0, -2, 0, 203, -2, 22101, 1, -3, -3, 21207, -2, 48, -1, 1205, -1, 1107, 22107, 57, -2, -1, 1205, -1, 1107, 21201, -2, -48, -2, 2201, -5, -4, 1090, 20102, 10, 0, -1, 22201, -2, -1, -2, 2201, -5, -4, 1103, 1202, -2, 1, 0, 1105, 1, 1060, 21208, -2, 10, -1, 1205, -1, 1162, 21208, -2, 44, -1, 1206, -1, 1131, 1106, 0, 989, 21101, 0, 439, 1, 1106, 0, 1150, 21102, 477, 1, 1, 1106, 0, 1150, 21101, 0, 514, 1, 21101, 1149, 0, 0, 1106, 0, 579, 99, 21101, 0, 1157, 0, 1105, 1, 579, 204, -2, 104, 10, 99, 21207, -3, 22, -1, 1206, -1, 1138, 1202, -5, 1, 1176, 1202, -4, 1, 0, 109, -6, 2106, 0, 0, 46, 7, 78, 1, 84, 1, 84, 1, 84, 1, 84, 1, 80, 13, 72, 1, 3, 1, 7, 1, 72, 1, 3, 1, 7, 1, 9, 11, 52, 1, 3, 1, 7, 1, 9, 1, 9, 1, 52, 1, 3, 1, 7, 1, 9, 1, 9, 1, 52, 1, 3, 1, 7, 1, 9, 1, 9, 1, 44, 13, 7, 1, 9, 1, 9, 1, 44, 1, 7, 1, 11, 1, 9, 1, 9, 1, 44, 1, 7, 1, 11, 1, 9, 1, 9, 1, 44, 1, 7, 1, 11, 1, 9, 1, 9, 1, 42, 11, 11, 1, 9, 1, 9, 1, 42, 1, 1, 1, 19, 1, 9, 1, 9, 1, 42, 1, 1, 1, 19, 11, 9, 11, 32, 1, 1, 1, 82, 1, 1, 1, 82, 1, 1, 1, 82, 1, 1, 1, 82, 1, 1, 1, 72, 13, 72, 1, 9, 1, 74, 1, 9, 11, 64, 1, 19, 1, 64, 1, 19, 1, 64, 1, 19, 1, 64, 1, 19, 1, 64, 1, 19, 1, 64, 1, 19, 1, 64, 1, 19, 1, 64, 11, 9, 1, 74, 1, 9, 1, 72, 13, 72, 1, 1, 1, 82, 1, 1, 1, 82, 1, 1, 1, 82, 1, 1, 1, 82, 1, 1, 1, 52, 11, 19, 1, 1, 1, 52, 1, 9, 1, 19, 1, 1, 1, 52, 1, 9, 1, 11, 11, 52, 1, 9, 1, 11, 1, 7, 1, 54, 1, 9, 1, 11, 1, 7, 1, 54, 1, 9, 1, 11, 1, 7, 1, 54, 13, 5, 13, 64, 1, 1, 1, 5, 1, 3, 1, 72, 1, 1, 1, 5, 1, 3, 1, 72, 1, 1, 1, 5, 1, 3, 1, 72, 1, 1, 1, 5, 1, 3, 1, 72, 1, 1, 1, 5, 1, 3, 1, 72, 13, 74, 1, 5, 1, 78, 1, 5, 1, 78, 1, 5, 1, 78, 1, 5, 1, 78, 1, 5, 1, 78, 7, 66}
 	p := NewProcess(code, []int{})
 
 	p.Run()
 
-	result := []byte{}
-
-	for _, b := range p.output {
-		result = append(result, byte(b))
-	}
-
-	strs := strings.Split(string(result[:len(result)-2]), "\n")
-
-	res := [][]byte{}
-
-	for _, line := range strs {
-		row := []byte{}
-
-		for _, c := range line {
-			row = append(row, byte(c))
-		}
-
-		res = append(res, row)
-	}
-
-	return res
-}
-
-func draw(m [][]byte) {
-	for i := 0; i < len(m); i++ {
-		fmt.Print("->  ")
-
-		for j := 0; j < len(m[i]); j++ {
-			fmt.Print(string(m[i][j]))
-		}
-
-		fmt.Println()
-	}
-}
-
-func findRobot(m [][]byte) (Pos, Pos) {
-	for i := 0; i < len(m); i++ {
-		for j := 0; j < len(m[i]); j++ {
-			if m[i][j] == '^' {
-				return Pos{X: j, Y: i}, Pos{X: 0, Y: -1}
-			}
-
-			if m[i][j] == 'v' {
-				return Pos{X: j, Y: i}, Pos{X: 0, Y: 1}
-			}
-
-			if m[i][j] == '<' {
-				return Pos{X: j, Y: i}, Pos{X: -1, Y: 0}
-			}
-
-			if m[i][j] == '>' {
-				return Pos{X: j, Y: i}, Pos{X: 1, Y: 0}
-			}
-		}
-	}
-
-	panic("robot is not found")
-}
-
-func isScaffold(pos Pos, m [][]byte) bool {
-	if pos.Y >= len(m) {
-		return false
-	}
-
-	if pos.X >= len(m[0]) {
-		return false
-	}
-
-	if pos.X < 0 {
-		return false
-	}
-
-	if pos.Y < 0 {
-		return false
-	}
-
-	return m[pos.Y][pos.X] == '#' || m[pos.Y][pos.X] == '@'
+	return readOutput(p)
 }
 
 func right(dir Pos) Pos {
@@ -651,45 +575,6 @@ func add(p1, p2 Pos) Pos {
 	return Pos{X: p1.X + p2.X, Y: p1.Y + p2.Y}
 }
 
-func path(pos Pos, dir Pos, m [][]byte) string {
-	name := "not-found"
-	newDir := Pos{}
-
-	if isScaffold(add(pos, left(dir)), m) {
-		name = "L"
-		newDir = left(dir)
-	}
-
-	if isScaffold(add(pos, right(dir)), m) {
-		name = "R"
-		newDir = right(dir)
-	}
-
-	if name == "not-found" {
-		return ""
-	}
-
-	dis := 0
-
-	newPos := Pos{}
-
-	for p := add(pos, newDir); isScaffold(p, m); p = add(p, newDir) {
-		m[p.Y][p.X] = '@'
-		dis++
-		newPos = p
-	}
-
-	rest := path(newPos, newDir, m)
-
-	draw(m)
-
-	if rest != "" {
-		return fmt.Sprintf("%s:%d,%s", name, dis, rest)
-	} else {
-		return fmt.Sprintf("%s:%d", name, dis)
-	}
-}
-
 func readOutput(p *Process) string {
 	result := []byte{}
 
@@ -756,93 +641,70 @@ func runRobot(main, a, b, c string) int {
 	return p.output[len(p.output)-1]
 }
 
-func segment(str string, segments int) ([]string, bool) {
-	if segments == 0 {
-		fmt.Println(str)
-		if str == "" {
-			return []string{}, true
-		}
-
-		return []string{}, false
-	}
-
-	parts := strings.Split(str, ",")
-
-	for _, elements := range []int{5, 4, 3, 2, 1} {
-		seg := strings.Join(parts[0:elements], ",")
-
-		if len(seg) > 20 {
-			continue
-		}
-
-		newStr := strings.Replace(str, seg+",", "", -1)
-		newStr = strings.Replace(newStr, seg, "", -1)
-
-		result := []string{seg}
-
-		rest, ok := segment(newStr, segments-1)
-
-		if ok {
-			return append(result, rest...), ok
-		}
+var example = `#######...#####
+#.....#...#...#
+#.....#...#...#
+......#...#...#
+......#...###.#
+......#.....#.#
+^########...#.#
+......#.#...#.#
+......#########
+........#...#..
+....#########..
+....#...#......
+....#...#......
+....#...#......
+....#####......
+`
+
+func solve(s *ScaffoldMap, opts CompressOptions) (*Compression, error) {
+	program, err := SolveRoute(s, opts, 10000)
+	if err != nil {
+		return nil, err
 	}
 
-	return []string{}, false
-}
-
-func compress(str string) (string, string, string, string) {
-	segs, ok := segment(str, 3)
-
-	if !ok {
-		panic("Compression failed")
+	if err := s.Covers(program.Moves()); err != nil {
+		return nil, err
 	}
 
-	str = strings.Replace(str, segs[0], "A", -1)
-	str = strings.Replace(str, segs[1], "B", -1)
-	str = strings.Replace(str, segs[2], "C", -1)
-
-	return str, segs[0], segs[1], segs[2]
+	return program, nil
 }
 
 func main() {
-	m := getMap()
-
-	draw(m)
-
-	result := 0
-
-	for i := 1; i < len(m)-1; i++ {
-		for j := 1; j < len(m[i])-1; j++ {
-			cond := m[i][j] == '#' &&
-				m[i+1][j] == '#' &&
-				m[i-1][j] == '#' &&
-				m[i][j+1] == '#' &&
-				m[i][j-1] == '#'
-
-			if cond {
-				result += i * j
-			}
-		}
+	s, err := ParseScaffold(example)
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	fmt.Println(result)
+	program, err := solve(s, CompressOptions{Functions: 3, MaxLength: 20})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	pos, dir := findRobot(m)
+	fmt.Println(program)
 
-	fmt.Println(pos, dir)
+	s, err = ParseScaffold(getMap())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	path := path(pos, dir, m)
+	fmt.Println(s)
+	fmt.Println(s.Alignment())
+	fmt.Println(s.Robot, s.Direction, s.DeadEnds())
 
-	main, a, b, c := compress(path)
+	program, err = solve(s, DefaultCompressOptions())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	fmt.Println(main, a, b, c)
+	fmt.Println(program)
 
-	dust := runRobot(
-		main,
-		strings.Replace(a, ":", ",", -1),
-		strings.Replace(b, ":", ",", -1),
-		strings.Replace(c, ":", ",", -1),
-	)
+	dust := runRobot(program.MainRoutine(), program.Function(0), program.Function(1), program.Function(2))
 
 	fmt.Println("dust", dust)
 }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+)
+
+//
+// Enumerating routes over the scaffold
+//
+// A route walks every stretch of scaffold exactly once. At every
+// intersection the robot may either go straight or turn, so a map has many
+// routes, and some of them compress better than others.
+//
+
+type edge struct {
+	from, to Pos
+}
+
+func newEdge(a, b Pos) edge {
+	if b.Y < a.Y || (b.Y == a.Y && b.X < a.X) {
+		a, b = b, a
+	}
+
+	return edge{from: a, to: b}
+}
+
+func (s *ScaffoldMap) edges() int {
+	count := 0
+
+	for _, pos := range s.Scaffold() {
+		if s.IsScaffold(add(pos, Pos{X: 1, Y: 0})) {
+			count++
+		}
+
+		if s.IsScaffold(add(pos, Pos{X: 0, Y: 1})) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// EnumerateRoutes calls visit with every route that covers the scaffold,
+// starting with the one that always goes straight through intersections.
+// Returning false from visit stops the enumeration.
+func EnumerateRoutes(s *ScaffoldMap, visit func(moves []string) bool) {
+	total := s.edges()
+	used := map[edge]bool{}
+	moves := []string{}
+
+	var walk func(pos, dir Pos) bool
+
+	walk = func(pos, dir Pos) bool {
+		if len(used) == total {
+			return visit(moves)
+		}
+
+		for _, turn := range []string{"L", "R"} {
+			newDir := left(dir)
+			if turn == "R" {
+				newDir = right(dir)
+			}
+
+			// the places where the robot could stop, it has to be able to
+			// turn there or the stretch has to end
+			stops := []int{}
+			cells := []Pos{}
+			p := pos
+
+			for {
+				next := add(p, newDir)
+
+				if !s.IsScaffold(next) || used[newEdge(p, next)] {
+					if len(cells) > 0 {
+						stops = append(stops, len(cells))
+					}
+					break
+				}
+
+				p = next
+				cells = append(cells, p)
+
+				if s.IsScaffold(add(p, left(newDir))) || s.IsScaffold(add(p, right(newDir))) {
+					stops = append(stops, len(cells))
+				}
+			}
+
+			// longest first, so the straight route comes out first
+			for i := len(stops) - 1; i >= 0; i-- {
+				if i < len(stops)-1 && stops[i] == stops[i+1] {
+					continue
+				}
+
+				distance := stops[i]
+				from := pos
+
+				for _, c := range cells[:distance] {
+					used[newEdge(from, c)] = true
+					from = c
+				}
+
+				moves = append(moves, fmt.Sprintf("%s,%d", turn, distance))
+
+				more := walk(cells[distance-1], newDir)
+
+				moves = moves[:len(moves)-1]
+				from = pos
+
+				for _, c := range cells[:distance] {
+					delete(used, newEdge(from, c))
+					from = c
+				}
+
+				if !more {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+
+	walk(s.Robot, s.Direction)
+}
+
+// SolveRoute feeds routes to the compressor until one of them fits. At most
+// maxRoutes routes are tried, 0 means all of them.
+func SolveRoute(s *ScaffoldMap, opts CompressOptions, maxRoutes int) (*Compression, error) {
+	var result *Compression
+	var lastErr error
+
+	tried := 0
+
+	EnumerateRoutes(s, func(moves []string) bool {
+		tried++
+
+		result, lastErr = Compress(moves, opts)
+		if lastErr == nil {
+			return false
+		}
+
+		return maxRoutes == 0 || tried < maxRoutes
+	})
+
+	if tried == 0 {
+		return nil, fmt.Errorf("No route covers the scaffold")
+	}
+
+	if lastErr != nil {
+		return nil, fmt.Errorf("None of the %d routes could be compressed: %s", tried, lastErr)
+	}
+
+	return result, nil
+}
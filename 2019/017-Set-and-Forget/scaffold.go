@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScaffoldMap is the camera view of the scaffold. The robot always stands
+// on scaffold.
+type ScaffoldMap struct {
+	cells     [][]byte
+	Robot     Pos
+	Direction Pos
+}
+
+var robotDirections = map[byte]Pos{
+	'^': Pos{X: 0, Y: -1},
+	'v': Pos{X: 0, Y: 1},
+	'<': Pos{X: -1, Y: 0},
+	'>': Pos{X: 1, Y: 0},
+}
+
+// ParseScaffold reads the camera output. Empty lines at the end are
+// ignored and short lines are padded with open space.
+func ParseScaffold(text string) (*ScaffoldMap, error) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	width := 0
+
+	for _, line := range lines {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+
+	s := &ScaffoldMap{}
+	robots := 0
+
+	for y, line := range lines {
+		row := []byte(line + strings.Repeat(".", width-len(line)))
+
+		for x, c := range row {
+			switch c {
+			case '#', '.':
+			case 'X':
+				return nil, fmt.Errorf("Robot is tumbling through space at %d,%d", x, y)
+			default:
+				dir, ok := robotDirections[c]
+				if !ok {
+					return nil, fmt.Errorf("Unknown tile %q at %d,%d", c, x, y)
+				}
+
+				s.Robot = Pos{X: x, Y: y}
+				s.Direction = dir
+				robots++
+			}
+		}
+
+		s.cells = append(s.cells, row)
+	}
+
+	if robots != 1 {
+		return nil, fmt.Errorf("Expected one robot, found %d", robots)
+	}
+
+	return s, nil
+}
+
+func (s *ScaffoldMap) Width() int {
+	if len(s.cells) == 0 {
+		return 0
+	}
+
+	return len(s.cells[0])
+}
+
+func (s *ScaffoldMap) Height() int {
+	return len(s.cells)
+}
+
+func (s *ScaffoldMap) IsScaffold(pos Pos) bool {
+	if pos.Y < 0 || pos.Y >= s.Height() || pos.X < 0 || pos.X >= s.Width() {
+		return false
+	}
+
+	return pos == s.Robot || s.cells[pos.Y][pos.X] == '#'
+}
+
+// Scaffold lists every scaffold cell row by row.
+func (s *ScaffoldMap) Scaffold() []Pos {
+	result := []Pos{}
+
+	for y := 0; y < s.Height(); y++ {
+		for x := 0; x < s.Width(); x++ {
+			if pos := (Pos{X: x, Y: y}); s.IsScaffold(pos) {
+				result = append(result, pos)
+			}
+		}
+	}
+
+	return result
+}
+
+func (s *ScaffoldMap) neighbours(pos Pos) int {
+	count := 0
+
+	for _, d := range robotDirections {
+		if s.IsScaffold(add(pos, d)) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Intersections are scaffold cells with scaffold on all four sides.
+func (s *ScaffoldMap) Intersections() []Pos {
+	result := []Pos{}
+
+	for _, pos := range s.Scaffold() {
+		if s.neighbours(pos) == 4 {
+			result = append(result, pos)
+		}
+	}
+
+	return result
+}
+
+// Alignment is the sum of x*y over all intersections.
+func (s *ScaffoldMap) Alignment() int {
+	result := 0
+
+	for _, pos := range s.Intersections() {
+		result += pos.X * pos.Y
+	}
+
+	return result
+}
+
+// DeadEnds are scaffold cells with only one scaffold neighbour, the route
+// has to start or end there.
+func (s *ScaffoldMap) DeadEnds() []Pos {
+	result := []Pos{}
+
+	for _, pos := range s.Scaffold() {
+		if s.neighbours(pos) == 1 {
+			result = append(result, pos)
+		}
+	}
+
+	return result
+}
+
+// Visit runs the moves ("L,10", "R,4", ...) from the robot's pose and
+// returns the cells it passed. Falling off the scaffold is an error.
+func (s *ScaffoldMap) Visit(moves []string) (map[Pos]bool, error) {
+	pos, dir := s.Robot, s.Direction
+	visited := map[Pos]bool{pos: true}
+
+	for i, m := range moves {
+		parts := strings.Split(m, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Move %d: invalid move %q", i+1, m)
+		}
+
+		switch parts[0] {
+		case "L":
+			dir = left(dir)
+		case "R":
+			dir = right(dir)
+		default:
+			return nil, fmt.Errorf("Move %d: unknown turn %q", i+1, parts[0])
+		}
+
+		distance, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("Move %d: invalid distance %q", i+1, parts[1])
+		}
+
+		for step := 0; step < distance; step++ {
+			pos = add(pos, dir)
+
+			if !s.IsScaffold(pos) {
+				return nil, fmt.Errorf("Move %d: robot falls off the scaffold at %d,%d", i+1, pos.X, pos.Y)
+			}
+
+			visited[pos] = true
+		}
+	}
+
+	return visited, nil
+}
+
+// Covers checks that the moves visit every scaffold cell.
+func (s *ScaffoldMap) Covers(moves []string) error {
+	visited, err := s.Visit(moves)
+	if err != nil {
+		return err
+	}
+
+	missed := []string{}
+
+	for _, pos := range s.Scaffold() {
+		if !visited[pos] {
+			missed = append(missed, fmt.Sprintf("%d,%d", pos.X, pos.Y))
+		}
+	}
+
+	if len(missed) > 0 {
+		return fmt.Errorf("Route misses %d scaffold cells: %s", len(missed), strings.Join(missed, " "))
+	}
+
+	return nil
+}
+
+func (s *ScaffoldMap) String() string {
+	lines := []string{}
+
+	for _, row := range s.cells {
+		lines = append(lines, string(row))
+	}
+
+	return strings.Join(lines, "\n")
+}
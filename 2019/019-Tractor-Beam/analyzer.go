@@ -0,0 +1,463 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//
+// Static analysis of intcode programs
+//
+// The program is decoded by recursive descent from address 0 with Decode,
+// the decoder Opcodes uses linearly. Jumps through memory (e.g. returns via the
+// relative base) have no static target, so every code address that the
+// program pushes as a return address is treated as a possible target.
+//
+
+func (in Instruction) isJump() bool {
+	return in.Opcode == OpcodeJumpIfTrue || in.Opcode == OpcodeJumpIfFalse
+}
+
+// writes returns the index of the parameter the instruction writes to.
+func (in Instruction) writes() (int, bool) {
+	switch in.Opcode {
+	case OpcodeAdd, OpcodeMultiply, OpcodeLessThan, OpcodeEquals:
+		return 2, true
+	case OpcodeGetInput:
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// Successors of an instruction. The bool is false when the jump target is
+// only known at runtime.
+func (in Instruction) successors() ([]int, bool) {
+	next := in.Address + in.Length()
+
+	switch {
+	case in.Opcode == OpcodeHalt:
+		return nil, true
+	case in.isJump():
+		taken, falls := true, true
+
+		if in.Modes[0] == InputModeImmidiate {
+			nonZero := in.Params[0] != 0
+			taken = nonZero == (in.Opcode == OpcodeJumpIfTrue)
+			falls = !taken
+		}
+
+		result := []int{}
+		if falls {
+			result = append(result, next)
+		}
+
+		if !taken {
+			return result, true
+		}
+
+		if in.Modes[1] != InputModeImmidiate {
+			return result, false
+		}
+
+		return append(result, in.Params[1]), true
+	default:
+		return []int{next}, true
+	}
+}
+
+//
+// Analysis
+//
+
+type BasicBlock struct {
+	Start        int
+	Instructions []Instruction
+	Successors   []int
+	Indirect     bool
+}
+
+type Finding struct {
+	Address int
+	Message string
+}
+
+type Analysis struct {
+	code         []int
+	Instructions map[int]Instruction
+	Blocks       map[int]*BasicBlock
+
+	IndirectTargets  []int
+	SelfModifying    []Finding
+	UninitializedUse []Finding
+	DecodeErrors     []Finding
+	InputSites       []int
+	OutputSites      []int
+	Unreachable      [][2]int
+	Data             [][2]int
+}
+
+func Analyze(code []int) *Analysis {
+	a := &Analysis{
+		code:         code,
+		Instructions: map[int]Instruction{},
+		Blocks:       map[int]*BasicBlock{},
+	}
+
+	a.explore()
+	a.buildBlocks()
+	a.findIssues()
+
+	return a
+}
+
+// explore decodes every reachable instruction, adding stored code addresses
+// as entry points until nothing new is found.
+func (a *Analysis) explore() {
+	targets := map[int]bool{}
+	failed := map[int]bool{}
+	queue := []int{0}
+
+	for len(queue) > 0 {
+		for len(queue) > 0 {
+			address := queue[0]
+			queue = queue[1:]
+
+			if _, seen := a.Instructions[address]; seen || failed[address] {
+				continue
+			}
+
+			in, err := Decode(a.code, address)
+			if err != nil {
+				failed[address] = true
+				a.DecodeErrors = append(a.DecodeErrors, Finding{Address: address, Message: err.Error()})
+				continue
+			}
+
+			a.Instructions[address] = in
+
+			next, _ := in.successors()
+			queue = append(queue, next...)
+
+			if v, ok := in.storedConstant(); ok && v > 0 && v < len(a.code) {
+				targets[v] = true
+			}
+		}
+
+		if !a.hasIndirectJump() {
+			break
+		}
+
+		for _, t := range sortedKeys(targets) {
+			if _, seen := a.Instructions[t]; seen || a.insideInstruction(t) {
+				continue
+			}
+
+			if _, err := Decode(a.code, t); err == nil {
+				queue = append(queue, t)
+			}
+		}
+	}
+
+	if a.hasIndirectJump() {
+		for _, t := range sortedKeys(targets) {
+			if _, ok := a.Instructions[t]; ok {
+				a.IndirectTargets = append(a.IndirectTargets, t)
+			}
+		}
+	}
+}
+
+func (a *Analysis) insideInstruction(address int) bool {
+	for _, in := range a.Instructions {
+		if address > in.Address && address < in.Address+in.Length() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// storedConstant recognises "[rb+0] = 0 + c" and "[rb+0] = 1 * c", the way
+// programs push the return address before a call.
+func (in Instruction) storedConstant() (int, bool) {
+	if in.Opcode != OpcodeAdd && in.Opcode != OpcodeMultiply {
+		return 0, false
+	}
+
+	if in.Modes[2] != InputModeRelative || in.Params[2] != 0 {
+		return 0, false
+	}
+
+	if in.Modes[0] != InputModeImmidiate || in.Modes[1] != InputModeImmidiate {
+		return 0, false
+	}
+
+	neutral := 0
+	if in.Opcode == OpcodeMultiply {
+		neutral = 1
+	}
+
+	switch {
+	case in.Params[0] == neutral:
+		return in.Params[1], true
+	case in.Params[1] == neutral:
+		return in.Params[0], true
+	}
+
+	return 0, false
+}
+
+func (a *Analysis) hasIndirectJump() bool {
+	for _, in := range a.Instructions {
+		if _, static := in.successors(); !static {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *Analysis) buildBlocks() {
+	leaders := map[int]bool{0: true}
+
+	for _, t := range a.IndirectTargets {
+		leaders[t] = true
+	}
+
+	for _, in := range a.Instructions {
+		if !in.isJump() && in.Opcode != OpcodeHalt {
+			continue
+		}
+
+		next, _ := in.successors()
+		for _, n := range next {
+			leaders[n] = true
+		}
+
+		leaders[in.Address+in.Length()] = true
+	}
+
+	for leader := range leaders {
+		if _, ok := a.Instructions[leader]; !ok {
+			continue
+		}
+
+		block := &BasicBlock{Start: leader}
+		address := leader
+
+		for {
+			in := a.Instructions[address]
+			block.Instructions = append(block.Instructions, in)
+
+			next, static := in.successors()
+			end := in.isJump() || in.Opcode == OpcodeHalt
+
+			following := in.Address + in.Length()
+			if _, ok := a.Instructions[following]; !ok || leaders[following] {
+				end = true
+			}
+
+			if end {
+				block.Successors = next
+				block.Indirect = !static
+				break
+			}
+
+			address = following
+		}
+
+		a.Blocks[leader] = block
+	}
+}
+
+func (a *Analysis) findIssues() {
+	covered := make([]bool, len(a.code))
+
+	// cells the program addresses directly, or that follow the last
+	// instruction, hold its data rather than unreachable code
+	end := 0
+	accessed := map[int]bool{}
+
+	for _, in := range a.Instructions {
+		for k := 0; k < in.Length(); k++ {
+			covered[in.Address+k] = true
+		}
+
+		if in.Address+in.Length() > end {
+			end = in.Address + in.Length()
+		}
+
+		for k, v := range in.Params {
+			if in.Modes[k] == InputModePosition {
+				accessed[v] = true
+			}
+		}
+	}
+
+	for _, address := range sortedKeys(a.instructionSet()) {
+		in := a.Instructions[address]
+
+		switch in.Opcode {
+		case OpcodeGetInput:
+			a.InputSites = append(a.InputSites, address)
+		case OpcodeWriteOutput:
+			a.OutputSites = append(a.OutputSites, address)
+		}
+
+		written := -1
+		if k, ok := in.writes(); ok {
+			written = k
+
+			if in.Modes[k] == InputModePosition && in.Params[k] >= 0 && in.Params[k] < len(a.code) && covered[in.Params[k]] {
+				a.SelfModifying = append(a.SelfModifying, Finding{Address: address, Message: fmt.Sprintf("writes into code at %d", in.Params[k])})
+			}
+		}
+
+		for k, v := range in.Params {
+			if k == written || in.Modes[k] != InputModePosition {
+				continue
+			}
+
+			if v >= len(a.code) {
+				a.UninitializedUse = append(a.UninitializedUse, Finding{Address: address, Message: fmt.Sprintf("reads %d beyond the program (length %d)", v, len(a.code))})
+			}
+		}
+	}
+
+	from := -1
+	for i := 0; i <= len(a.code); i++ {
+		if i < len(a.code) && !covered[i] {
+			if from == -1 {
+				from = i
+			}
+			continue
+		}
+
+		if from == -1 {
+			continue
+		}
+
+		data := from >= end
+		for k := from; k < i; k++ {
+			data = data || accessed[k]
+		}
+
+		if data {
+			a.Data = append(a.Data, [2]int{from, i - 1})
+		} else {
+			a.Unreachable = append(a.Unreachable, [2]int{from, i - 1})
+		}
+
+		from = -1
+	}
+}
+
+func (a *Analysis) instructionSet() map[int]bool {
+	result := map[int]bool{}
+	for address := range a.Instructions {
+		result[address] = true
+	}
+	return result
+}
+
+func sortedKeys(m map[int]bool) []int {
+	result := []int{}
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Ints(result)
+	return result
+}
+
+//
+// Output
+//
+
+func (a *Analysis) Report() string {
+	lines := []string{
+		fmt.Sprintf("program length:     %d", len(a.code)),
+		fmt.Sprintf("instructions:       %d in %d blocks", len(a.Instructions), len(a.Blocks)),
+		fmt.Sprintf("indirect targets:   %v", a.IndirectTargets),
+		fmt.Sprintf("input sites:        %v", a.InputSites),
+		fmt.Sprintf("output sites:       %v", a.OutputSites),
+	}
+
+	sections := []struct {
+		title    string
+		findings []Finding
+	}{
+		{"self modifying writes", a.SelfModifying},
+		{"uninitialized reads", a.UninitializedUse},
+		{"decode errors", a.DecodeErrors},
+	}
+
+	for _, section := range sections {
+		lines = append(lines, fmt.Sprintf("%s: %d", section.title, len(section.findings)))
+
+		for _, f := range section.findings {
+			lines = append(lines, fmt.Sprintf("  %4d: %s", f.Address, f.Message))
+		}
+	}
+
+	lines = append(lines, fmt.Sprintf("unreachable ranges: %d", len(a.Unreachable)))
+	for _, r := range a.Unreachable {
+		lines = append(lines, fmt.Sprintf("  %4d..%d", r[0], r[1]))
+	}
+
+	lines = append(lines, fmt.Sprintf("data ranges:        %d", len(a.Data)))
+	for _, r := range a.Data {
+		lines = append(lines, fmt.Sprintf("  %4d..%d", r[0], r[1]))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Dot renders the control flow graph in Graphviz format. Jumps through memory
+// point to a shared "indirect" node.
+func (a *Analysis) Dot() string {
+	lines := []string{"digraph cfg {", "  node [shape=box fontname=monospace];"}
+
+	starts := []int{}
+	for start := range a.Blocks {
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+
+	indirect := false
+
+	for _, start := range starts {
+		block := a.Blocks[start]
+
+		body := []string{}
+		for _, in := range block.Instructions {
+			body = append(body, in.String())
+		}
+
+		lines = append(lines, fmt.Sprintf("  b%d [label=\"%s\\l\"];", start, strings.Join(body, "\\l")))
+
+		for _, s := range block.Successors {
+			if _, ok := a.Blocks[s]; ok {
+				lines = append(lines, fmt.Sprintf("  b%d -> b%d;", start, s))
+			}
+		}
+
+		if block.Indirect {
+			indirect = true
+			lines = append(lines, fmt.Sprintf("  b%d -> indirect [style=dashed];", start))
+		}
+	}
+
+	if indirect {
+		lines = append(lines, "  indirect [shape=ellipse];")
+
+		for _, t := range a.IndirectTargets {
+			lines = append(lines, fmt.Sprintf("  indirect -> b%d [style=dashed];", t))
+		}
+	}
+
+	lines = append(lines, "}")
+
+	return strings.Join(lines, "\n")
+}
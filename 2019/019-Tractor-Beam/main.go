@@ -1,7 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 const debug = false
@@ -25,6 +30,104 @@ const (
 	InputModeRelative  = 2
 )
 
+//
+// Decoding
+//
+
+type Instruction struct {
+	Address int
+	Opcode  int
+	Modes   []int
+	Params  []int
+}
+
+// OpcodeInfo is the mnemonic and the length, opcode included, of an
+// instruction.
+type OpcodeInfo struct {
+	Name   string
+	Length int
+}
+
+var Opcodes = map[int]OpcodeInfo{
+	OpcodeAdd:                {"ADD", 4},
+	OpcodeMultiply:           {"MUL", 4},
+	OpcodeGetInput:           {"GET", 2},
+	OpcodeWriteOutput:        {"WRT", 2},
+	OpcodeJumpIfTrue:         {"JIT", 3},
+	OpcodeJumpIfFalse:        {"JIF", 3},
+	OpcodeLessThan:           {"LT", 4},
+	OpcodeEquals:             {"EQL", 4},
+	OpcodeAdjustRelativeBase: {"ADJ", 2},
+	OpcodeHalt:               {"HALT", 1},
+}
+
+// ParamMode returns the mode of the k-th parameter (starting at 1) of an
+// operation.
+func ParamMode(operation int, k int) int {
+	return (operation / pow10(k+1)) % 10
+}
+
+func Decode(code []int, address int) (Instruction, error) {
+	if address < 0 || address >= len(code) {
+		return Instruction{}, fmt.Errorf("Address %d is outside of the program", address)
+	}
+
+	opcode := code[address] % 100
+
+	info, ok := Opcodes[opcode]
+	if !ok || code[address] < 0 {
+		return Instruction{}, fmt.Errorf("Unknonwn opcode %d at %d", code[address], address)
+	}
+
+	if address+info.Length > len(code) {
+		return Instruction{}, fmt.Errorf("Instruction at %d is cut off", address)
+	}
+
+	in := Instruction{Address: address, Opcode: opcode}
+
+	for k := 1; k < info.Length; k++ {
+		mode := ParamMode(code[address], k)
+
+		if mode > InputModeRelative {
+			return Instruction{}, fmt.Errorf("Invalid mode %d at %d", mode, address)
+		}
+
+		in.Modes = append(in.Modes, mode)
+		in.Params = append(in.Params, code[address+k])
+	}
+
+	return in, nil
+}
+
+func pow10(n int) int {
+	result := 1
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
+
+func (in Instruction) Length() int {
+	return Opcodes[in.Opcode].Length
+}
+
+func (in Instruction) String() string {
+	params := []string{}
+
+	for k, v := range in.Params {
+		switch in.Modes[k] {
+		case InputModePosition:
+			params = append(params, fmt.Sprintf("[%d]", v))
+		case InputModeImmidiate:
+			params = append(params, fmt.Sprintf("%d", v))
+		case InputModeRelative:
+			params = append(params, fmt.Sprintf("[rb%+d]", v))
+		}
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%4d: %-4s %s", in.Address, Opcodes[in.Opcode].Name, strings.Join(params, " ")))
+}
+
 type Process struct {
 	code          []int
 	memory        []int
@@ -136,15 +239,18 @@ func (p *Process) LoadParam(position int, mode int) (int, error) {
 	}
 }
 
-func (p *Process) Debug(name string, length int) {
+func (p *Process) Debug(opcode int) {
 	if !debug {
 		return
 	}
 
+	name := Opcodes[opcode].Name
+	length := Opcodes[opcode].Length - 1
+
 	fmt.Printf("%4s ", name)
 
-	mode1 := (p.memory[p.position] / 100) % 10
-	mode2 := (p.memory[p.position] / 1000) % 10
+	mode1 := ParamMode(p.memory[p.position], 1)
+	mode2 := ParamMode(p.memory[p.position], 2)
 
 	if length >= 1 {
 		fmt.Printf(" %10d ", p.memory[p.position+1])
@@ -195,15 +301,16 @@ func (p *Process) Opcodes() []string {
 
 	i := 0
 
-	for {
+	for i < len(p.code) {
 		instruction := p.code[i] % 100
 
 		fmt.Println(i, instruction)
 
-		modes := []int{-1, -1, -1}
-		modes[0] = (p.code[i] / 100) % 10
-		modes[1] = (p.code[i] / 1000) % 10
-		modes[2] = (p.code[i] / 10000) % 10
+		in, err := Decode(p.code, i)
+		if err != nil {
+			i += 1
+			continue
+		}
 
 		// toStr := func(name string, l int) string {
 		// 	res := fmt.Sprintf("%4d: %4s", i, name)
@@ -231,13 +338,8 @@ func (p *Process) Opcodes() []string {
 		// }
 
 		param := func(i int, param int) string {
-			modes := []int{-1, -1, -1, -1}
-			modes[1] = (p.code[i] / 100) % 10
-			modes[2] = (p.code[i] / 1000) % 10
-			modes[3] = (p.code[i] / 10000) % 10
-
-			m := modes[param]
-			v := p.code[i+param]
+			m := in.Modes[param-1]
+			v := in.Params[param-1]
 
 			if m == 0 {
 				return fmt.Sprintf("reg[%d]", v)
@@ -264,65 +366,41 @@ func (p *Process) Opcodes() []string {
 			panic("AAA")
 		}
 
-		switch instruction {
+		switch in.Opcode {
 		case OpcodeAdd:
 			str := fmt.Sprintf("%s = %s + %s", param(i, 3), param(i, 1), param(i, 2))
 
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-			i += 4
 		case OpcodeMultiply:
 			str := fmt.Sprintf("%s = %s * %s", param(i, 3), param(i, 1), param(i, 2))
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-
-			i += 4
 		case OpcodeGetInput:
 			str := fmt.Sprintf("%s = getInput()", param(i, 1))
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-
-			i += 2
 		case OpcodeWriteOutput:
 			str := fmt.Sprintf("puts(%s)", param(i, 1))
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-
-			i += 2
 		case OpcodeJumpIfTrue:
 			str := fmt.Sprintf("if(%s == 1) goto %s", param(i, 1), param(i, 2))
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-
-			i += 3
 		case OpcodeJumpIfFalse:
 			str := fmt.Sprintf("if(%s == 0) goto %s", param(i, 1), param(i, 2))
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-
-			i += 3
 		case OpcodeLessThan:
 			str := fmt.Sprintf("if(%s < %s) { %s = 1 } else { %s = 0 }", param(i, 1), param(i, 2), param(i, 3), param(i, 3))
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-
-			i += 4
 		case OpcodeEquals:
 			str := fmt.Sprintf("if(%s == %s) { %s = 1 } else { %s = 0 }", param(i, 1), param(i, 2), param(i, 3), param(i, 3))
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-
-			i += 4
 		case OpcodeAdjustRelativeBase:
 			str := fmt.Sprintf("pointer = %s", param(i, 1))
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-
-			i += 2
 		case OpcodeHalt:
 			str := fmt.Sprintf("HALT")
 			result = append(result, fmt.Sprintf("%4d: %s", i, str))
-
-			i += 1
-		default:
-			i += 1
-			// goto a
 		}
 
-		if i >= len(p.code) {
-			break
-		}
+		i += in.Length()
 	}
 
 	return result
@@ -344,9 +422,9 @@ func (p *Process) RunTilInterupt() error {
 
 	instruction := operation % 100
 
-	param1Mode := (operation / 100) % 10
-	param2Mode := (operation / 1000) % 10
-	param3Mode := (operation / 10000) % 10
+	param1Mode := ParamMode(operation, 1)
+	param2Mode := ParamMode(operation, 2)
+	param3Mode := ParamMode(operation, 3)
 
 	if debug {
 		fmt.Printf("[%d %d %d %2d] ", param1Mode, param2Mode, param3Mode, instruction)
@@ -354,7 +432,7 @@ func (p *Process) RunTilInterupt() error {
 
 	switch instruction {
 	case OpcodeAdd:
-		p.Debug("ADD", 3)
+		p.Debug(OpcodeAdd)
 
 		value1, err := p.LoadParam(p.position+1, param1Mode)
 		if err != nil {
@@ -372,7 +450,7 @@ func (p *Process) RunTilInterupt() error {
 
 		return p.RunTilInterupt()
 	case OpcodeMultiply:
-		p.Debug("MUL", 3)
+		p.Debug(OpcodeMultiply)
 
 		value1, err := p.LoadParam(p.position+1, param1Mode)
 		if err != nil {
@@ -390,7 +468,7 @@ func (p *Process) RunTilInterupt() error {
 
 		return p.RunTilInterupt()
 	case OpcodeGetInput:
-		p.Debug("GET", 1)
+		p.Debug(OpcodeGetInput)
 
 		if p.inputPointer == len(p.input) {
 			// no input, program needs to complete
@@ -406,7 +484,7 @@ func (p *Process) RunTilInterupt() error {
 
 		return p.RunTilInterupt()
 	case OpcodeWriteOutput:
-		p.Debug("WRT", 1)
+		p.Debug(OpcodeWriteOutput)
 
 		value, err := p.LoadParam(p.position+1, param1Mode)
 		if err != nil {
@@ -419,7 +497,7 @@ func (p *Process) RunTilInterupt() error {
 
 		return p.RunTilInterupt()
 	case OpcodeJumpIfTrue:
-		p.Debug("JIT", 2)
+		p.Debug(OpcodeJumpIfTrue)
 
 		value1, err := p.LoadParam(p.position+1, param1Mode)
 		if err != nil {
@@ -447,7 +525,7 @@ func (p *Process) RunTilInterupt() error {
 		return p.RunTilInterupt()
 
 	case OpcodeJumpIfFalse:
-		p.Debug("JIF", 2)
+		p.Debug(OpcodeJumpIfFalse)
 
 		value1, err := p.LoadParam(p.position+1, param1Mode)
 		if err != nil {
@@ -475,7 +553,7 @@ func (p *Process) RunTilInterupt() error {
 
 		return p.RunTilInterupt()
 	case OpcodeLessThan:
-		p.Debug("LT", 3)
+		p.Debug(OpcodeLessThan)
 
 		value1, err := p.LoadParam(p.position+1, param1Mode)
 		if err != nil {
@@ -505,7 +583,7 @@ func (p *Process) RunTilInterupt() error {
 
 		return p.RunTilInterupt()
 	case OpcodeEquals:
-		p.Debug("EQL", 3)
+		p.Debug(OpcodeEquals)
 
 		value1, err := p.LoadParam(p.position+1, param1Mode)
 		if err != nil {
@@ -536,7 +614,7 @@ func (p *Process) RunTilInterupt() error {
 		return p.RunTilInterupt()
 
 	case OpcodeAdjustRelativeBase:
-		p.Debug("ADJ", 1)
+		p.Debug(OpcodeAdjustRelativeBase)
 
 		value, err := p.LoadParam(p.position+1, param1Mode)
 		if err != nil {
@@ -687,8 +765,10 @@ func (m *Map) draw(x, y int, size int) {
 
 }
 
+var beamCode = []int{109, 424, 203, 1, 21101, 0, 11, 0, 1106, 0, 282, 21102, 18, 1, 0, 1106, 0, 259, 2101, 0, 1, 221, 203, 1, 21102, 1, 31, 0, 1105, 1, 282, 21101, 0, 38, 0, 1106, 0, 259, 20102, 1, 23, 2, 22101, 0, 1, 3, 21102, 1, 1, 1, 21101, 57, 0, 0, 1105, 1, 303, 2102, 1, 1, 222, 20101, 0, 221, 3, 21002, 221, 1, 2, 21101, 0, 259, 1, 21102, 1, 80, 0, 1105, 1, 225, 21102, 125, 1, 2, 21102, 1, 91, 0, 1106, 0, 303, 2101, 0, 1, 223, 21002, 222, 1, 4, 21102, 1, 259, 3, 21102, 225, 1, 2, 21102, 225, 1, 1, 21101, 0, 118, 0, 1106, 0, 225, 20102, 1, 222, 3, 21101, 0, 69, 2, 21102, 1, 133, 0, 1106, 0, 303, 21202, 1, -1, 1, 22001, 223, 1, 1, 21102, 148, 1, 0, 1106, 0, 259, 1201, 1, 0, 223, 20101, 0, 221, 4, 21001, 222, 0, 3, 21102, 1, 22, 2, 1001, 132, -2, 224, 1002, 224, 2, 224, 1001, 224, 3, 224, 1002, 132, -1, 132, 1, 224, 132, 224, 21001, 224, 1, 1, 21102, 195, 1, 0, 106, 0, 108, 20207, 1, 223, 2, 20101, 0, 23, 1, 21102, -1, 1, 3, 21101, 0, 214, 0, 1105, 1, 303, 22101, 1, 1, 1, 204, 1, 99, 0, 0, 0, 0, 109, 5, 1202, -4, 1, 249, 21202, -3, 1, 1, 22102, 1, -2, 2, 21201, -1, 0, 3, 21101, 250, 0, 0, 1106, 0, 225, 22102, 1, 1, -4, 109, -5, 2105, 1, 0, 109, 3, 22107, 0, -2, -1, 21202, -1, 2, -1, 21201, -1, -1, -1, 22202, -1, -2, -2, 109, -3, 2106, 0, 0, 109, 3, 21207, -2, 0, -1, 1206, -1, 294, 104, 0, 99, 22101, 0, -2, -2, 109, -3, 2106, 0, 0, 109, 5, 22207, -3, -4, -1, 1206, -1, 346, 22201, -4, -3, -4, 21202, -3, -1, -1, 22201, -4, -1, 2, 21202, 2, -1, -1, 22201, -4, -1, 1, 22102, 1, -2, 3, 21101, 0, 343, 0, 1106, 0, 303, 1105, 1, 415, 22207, -2, -3, -1, 1206, -1, 387, 22201, -3, -2, -3, 21202, -2, -1, -1, 22201, -3, -1, 3, 21202, 3, -1, -1, 22201, -3, -1, 2, 22102, 1, -4, 1, 21101, 384, 0, 0, 1106, 0, 303, 1106, 0, 415, 21202, -4, -1, -4, 22201, -4, -3, -4, 22202, -3, -2, -2, 22202, -2, -4, -4, 22202, -3, -2, -3, 21202, -4, -1, -2, 22201, -3, -2, 1, 21202, 1, 1, -4, 109, -5, 2105, 1, 0}
+
 func check(pos Pos) int {
-	code := []int{109, 424, 203, 1, 21101, 0, 11, 0, 1106, 0, 282, 21102, 18, 1, 0, 1106, 0, 259, 2101, 0, 1, 221, 203, 1, 21102, 1, 31, 0, 1105, 1, 282, 21101, 0, 38, 0, 1106, 0, 259, 20102, 1, 23, 2, 22101, 0, 1, 3, 21102, 1, 1, 1, 21101, 57, 0, 0, 1105, 1, 303, 2102, 1, 1, 222, 20101, 0, 221, 3, 21002, 221, 1, 2, 21101, 0, 259, 1, 21102, 1, 80, 0, 1105, 1, 225, 21102, 125, 1, 2, 21102, 1, 91, 0, 1106, 0, 303, 2101, 0, 1, 223, 21002, 222, 1, 4, 21102, 1, 259, 3, 21102, 225, 1, 2, 21102, 225, 1, 1, 21101, 0, 118, 0, 1106, 0, 225, 20102, 1, 222, 3, 21101, 0, 69, 2, 21102, 1, 133, 0, 1106, 0, 303, 21202, 1, -1, 1, 22001, 223, 1, 1, 21102, 148, 1, 0, 1106, 0, 259, 1201, 1, 0, 223, 20101, 0, 221, 4, 21001, 222, 0, 3, 21102, 1, 22, 2, 1001, 132, -2, 224, 1002, 224, 2, 224, 1001, 224, 3, 224, 1002, 132, -1, 132, 1, 224, 132, 224, 21001, 224, 1, 1, 21102, 195, 1, 0, 106, 0, 108, 20207, 1, 223, 2, 20101, 0, 23, 1, 21102, -1, 1, 3, 21101, 0, 214, 0, 1105, 1, 303, 22101, 1, 1, 1, 204, 1, 99, 0, 0, 0, 0, 109, 5, 1202, -4, 1, 249, 21202, -3, 1, 1, 22102, 1, -2, 2, 21201, -1, 0, 3, 21101, 250, 0, 0, 1106, 0, 225, 22102, 1, 1, -4, 109, -5, 2105, 1, 0, 109, 3, 22107, 0, -2, -1, 21202, -1, 2, -1, 21201, -1, -1, -1, 22202, -1, -2, -2, 109, -3, 2106, 0, 0, 109, 3, 21207, -2, 0, -1, 1206, -1, 294, 104, 0, 99, 22101, 0, -2, -2, 109, -3, 2106, 0, 0, 109, 5, 22207, -3, -4, -1, 1206, -1, 346, 22201, -4, -3, -4, 21202, -3, -1, -1, 22201, -4, -1, 2, 21202, 2, -1, -1, 22201, -4, -1, 1, 22102, 1, -2, 3, 21101, 0, 343, 0, 1106, 0, 303, 1105, 1, 415, 22207, -2, -3, -1, 1206, -1, 387, 22201, -3, -2, -3, 21202, -2, -1, -1, 22201, -3, -1, 3, 21202, 3, -1, -1, 22201, -3, -1, 2, 22102, 1, -4, 1, 21101, 384, 0, 0, 1106, 0, 303, 1106, 0, 415, 21202, -4, -1, -4, 22201, -4, -3, -4, 22202, -3, -2, -2, 22202, -2, -4, -4, 22202, -3, -2, -3, 21202, -4, -1, -2, 22201, -3, -2, 1, 21202, 1, 1, -4, 109, -5, 2105, 1, 0}
+	code := beamCode
 	p := NewProcess(code, []int{pos.X, pos.Y})
 
 	p.Run()
@@ -798,7 +878,75 @@ func part2() {
 	fmt.Println(x*10000 + y)
 }
 
+// loadProgram reads a comma separated intcode program.
+func loadProgram(filename string) ([]int, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	code := []int{}
+
+	for _, field := range strings.Split(strings.TrimSpace(string(content)), ",") {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value %q in %s", field, filename)
+		}
+
+		code = append(code, v)
+	}
+
+	return code, nil
+}
+
+// symbolic runs day 2 with the noun and verb left open, its control flow
+// doesn't depend on them so the output is a formula.
+func symbolic() {
+	// noun and verb are at 1 and 2
+	code, err := loadProgram("../002-1202-Program-Alarm/input.txt")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	p := NewSymbolicProcess(code, []*Expr{})
+	p.Patch(1, Var("noun"))
+	p.Patch(2, Var("verb"))
+
+	result, err := p.Run()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println(result.Report(false))
+}
+
+// analyze prints the report, the CFG is written to dir when it is set.
+func analyze(dir string) {
+	a := Analyze(beamCode)
+
+	fmt.Println(a.Report())
+
+	if dir == "" {
+		return
+	}
+
+	err := ioutil.WriteFile(filepath.Join(dir, "cfg.dot"), []byte(a.Dot()), 0644)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
 func main() {
+	out := flag.String("out", "", "directory to write cfg.dot to, nothing is written when empty")
+
+	flag.Parse()
+
+	analyze(*out)
+	fmt.Println("---------------")
+	symbolic()
+	fmt.Println("---------------")
 	part1()
 	fmt.Println("---------------")
 	part2()
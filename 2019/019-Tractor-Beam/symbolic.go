@@ -0,0 +1,585 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//
+// Symbolic expressions
+//
+// Leafs are constants and named variables, inner nodes are the four
+// operations an intcode program can perform on values. A load is a read
+// through an address that depends on the input.
+//
+
+const (
+	ExprConst    = "const"
+	ExprVar      = "var"
+	ExprAdd      = "+"
+	ExprMultiply = "*"
+	ExprLessThan = "<"
+	ExprEquals   = "=="
+	ExprLoad     = "load"
+)
+
+type Expr struct {
+	op    string
+	value int
+	name  string
+	left  *Expr
+	right *Expr
+}
+
+func Const(value int) *Expr {
+	return &Expr{op: ExprConst, value: value}
+}
+
+func Var(name string) *Expr {
+	return &Expr{op: ExprVar, name: name}
+}
+
+func Binary(op string, left, right *Expr) *Expr {
+	return Simplify(&Expr{op: op, left: left, right: right})
+}
+
+func (e *Expr) IsConst() bool {
+	return e.op == ExprConst
+}
+
+// HasLoad tells if the value of the expression depends on memory.
+func (e *Expr) HasLoad() bool {
+	switch e.op {
+	case ExprConst, ExprVar:
+		return false
+	case ExprLoad:
+		return true
+	}
+
+	return e.left.HasLoad() || e.right.HasLoad()
+}
+
+func (e *Expr) String() string {
+	switch e.op {
+	case ExprConst:
+		return fmt.Sprintf("%d", e.value)
+	case ExprVar:
+		return e.name
+	case ExprLessThan, ExprEquals:
+		return fmt.Sprintf("(%s %s %s ? 1 : 0)", e.left, e.op, e.right)
+	case ExprLoad:
+		return fmt.Sprintf("mem[%s]", e.left)
+	default:
+		return fmt.Sprintf("(%s %s %s)", e.left, e.op, e.right)
+	}
+}
+
+// Eval computes the value of the expression for the given variables.
+func (e *Expr) Eval(vars map[string]int) (int, error) {
+	switch e.op {
+	case ExprConst:
+		return e.value, nil
+	case ExprVar:
+		v, ok := vars[e.name]
+		if !ok {
+			return 0, fmt.Errorf("Variable %s is not bound", e.name)
+		}
+
+		return v, nil
+	case ExprLoad:
+		return 0, fmt.Errorf("Cannot evaluate %s without memory", e)
+	}
+
+	left, err := e.left.Eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	right, err := e.right.Eval(vars)
+	if err != nil {
+		return 0, err
+	}
+
+	return applyOp(e.op, left, right), nil
+}
+
+func applyOp(op string, a, b int) int {
+	switch op {
+	case ExprAdd:
+		return a + b
+	case ExprMultiply:
+		return a * b
+	case ExprLessThan:
+		if a < b {
+			return 1
+		}
+		return 0
+	case ExprEquals:
+		if a == b {
+			return 1
+		}
+		return 0
+	}
+
+	panic("unknown operation " + op)
+}
+
+//
+// Simplification
+//
+// Sums and products are normalised into a polynomial (monomial -> coefficient)
+// which folds constants, drops zero terms and merges like terms. Comparisons
+// are folded when both sides are constant, or the same and free of loads.
+//
+
+type Poly map[string]int
+
+func Simplify(e *Expr) *Expr {
+	switch e.op {
+	case ExprConst, ExprVar:
+		return e
+	case ExprLoad:
+		return &Expr{op: ExprLoad, left: Simplify(e.left)}
+	case ExprLessThan, ExprEquals:
+		left := Simplify(e.left)
+		right := Simplify(e.right)
+
+		if left.IsConst() && right.IsConst() {
+			return Const(applyOp(e.op, left.value, right.value))
+		}
+
+		// x == x is 1 and x < x is 0 for any x, unless x reads memory which
+		// may change between the two loads
+		if left.String() == right.String() && !left.HasLoad() {
+			if e.op == ExprEquals {
+				return Const(1)
+			}
+
+			return Const(0)
+		}
+
+		return &Expr{op: e.op, left: left, right: right}
+	}
+
+	poly, ok := toPoly(e)
+	if !ok {
+		return &Expr{op: e.op, left: Simplify(e.left), right: Simplify(e.right)}
+	}
+
+	return fromPoly(poly)
+}
+
+func toPoly(e *Expr) (Poly, bool) {
+	switch e.op {
+	case ExprConst:
+		return Poly{"": e.value}, true
+	case ExprVar:
+		return Poly{e.name: 1}, true
+	case ExprAdd, ExprMultiply:
+		left, ok := toPoly(e.left)
+		if !ok {
+			return nil, false
+		}
+
+		right, ok := toPoly(e.right)
+		if !ok {
+			return nil, false
+		}
+
+		result := Poly{}
+
+		if e.op == ExprAdd {
+			for m, c := range left {
+				result[m] += c
+			}
+			for m, c := range right {
+				result[m] += c
+			}
+		} else {
+			for m1, c1 := range left {
+				for m2, c2 := range right {
+					result[monomialProduct(m1, m2)] += c1 * c2
+				}
+			}
+		}
+
+		for m, c := range result {
+			if c == 0 {
+				delete(result, m)
+			}
+		}
+
+		return result, true
+	}
+
+	return nil, false
+}
+
+func monomialProduct(m1, m2 string) string {
+	factors := []string{}
+
+	for _, m := range []string{m1, m2} {
+		if m != "" {
+			factors = append(factors, strings.Split(m, "*")...)
+		}
+	}
+
+	sort.Strings(factors)
+
+	return strings.Join(factors, "*")
+}
+
+func fromPoly(poly Poly) *Expr {
+	monomials := []string{}
+	for m := range poly {
+		monomials = append(monomials, m)
+	}
+
+	// highest degree first, constant term last
+	sort.Slice(monomials, func(i, j int) bool {
+		di := strings.Count(monomials[i], "*")
+		dj := strings.Count(monomials[j], "*")
+
+		if (monomials[i] == "") != (monomials[j] == "") {
+			return monomials[j] == ""
+		}
+
+		if di != dj {
+			return di > dj
+		}
+
+		return monomials[i] < monomials[j]
+	})
+
+	var result *Expr
+
+	for _, m := range monomials {
+		var term *Expr
+
+		if m == "" {
+			term = Const(poly[m])
+		} else {
+			for _, name := range strings.Split(m, "*") {
+				if term == nil {
+					term = Var(name)
+				} else {
+					term = &Expr{op: ExprMultiply, left: term, right: Var(name)}
+				}
+			}
+
+			if poly[m] != 1 {
+				term = &Expr{op: ExprMultiply, left: Const(poly[m]), right: term}
+			}
+		}
+
+		if result == nil {
+			result = term
+		} else {
+			result = &Expr{op: ExprAdd, left: result, right: term}
+		}
+	}
+
+	if result == nil {
+		return Const(0)
+	}
+
+	return result
+}
+
+//
+// Symbolic execution
+//
+// A SymbolicProcess runs intcode like Process, but every memory cell holds an
+// expression. Execution succeeds as long as the control flow (opcodes,
+// addresses, jumps and the relative base) stays concrete.
+//
+
+type InputDependentError struct {
+	Position int
+	Reason   string
+	Expr     *Expr
+}
+
+func (e *InputDependentError) Error() string {
+	return fmt.Sprintf("Control flow depends on input at %d (%s): %s", e.Position, e.Reason, e.Expr)
+}
+
+type SymbolicProcess struct {
+	code         []int
+	memory       map[int]*Expr
+	position     int
+	relativeBase int
+	inputs       []*Expr
+	inputPointer int
+	output       []*Expr
+	maxSteps     int
+
+	halted bool
+}
+
+type SymbolicResult struct {
+	Outputs []*Expr
+	Memory  map[int]*Expr
+	Steps   int
+}
+
+func NewSymbolicProcess(code []int, inputs []*Expr) *SymbolicProcess {
+	return &SymbolicProcess{
+		code:     code,
+		memory:   map[int]*Expr{},
+		inputs:   inputs,
+		maxSteps: 1000000,
+	}
+}
+
+// Patch replaces a memory cell before the run, e.g. noun and verb in day 2.
+func (p *SymbolicProcess) Patch(position int, value *Expr) {
+	p.memory[position] = value
+}
+
+func (p *SymbolicProcess) Read(position int) (*Expr, error) {
+	if position < 0 {
+		return nil, fmt.Errorf("Index %d out of range", position)
+	}
+
+	if v, ok := p.memory[position]; ok {
+		return v, nil
+	}
+
+	if position < len(p.code) {
+		return Const(p.code[position]), nil
+	}
+
+	return Const(0), nil
+}
+
+func (p *SymbolicProcess) concrete(position int, reason string) (int, error) {
+	v, err := p.Read(position)
+	if err != nil {
+		return 0, err
+	}
+
+	if !v.IsConst() {
+		return 0, &InputDependentError{Position: p.position, Reason: reason, Expr: v}
+	}
+
+	return v.value, nil
+}
+
+func (p *SymbolicProcess) address(position int, mode int) (int, error) {
+	pointer, err := p.concrete(position, "address")
+	if err != nil {
+		return 0, err
+	}
+
+	switch mode {
+	case InputModePosition:
+		return pointer, nil
+	case InputModeRelative:
+		return pointer + p.relativeBase, nil
+	default:
+		return 0, fmt.Errorf("Invalid address mode %d at %d", mode, p.position)
+	}
+}
+
+func (p *SymbolicProcess) LoadParam(position int, mode int) (*Expr, error) {
+	if mode == InputModeImmidiate {
+		return p.Read(position)
+	}
+
+	address, err := p.address(position, mode)
+	if dependent, ok := err.(*InputDependentError); ok {
+		pointer := dependent.Expr
+		if mode == InputModeRelative {
+			pointer = Binary(ExprAdd, pointer, Const(p.relativeBase))
+		}
+
+		return &Expr{op: ExprLoad, left: pointer}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Read(address)
+}
+
+func (p *SymbolicProcess) Write(position int, value *Expr, mode int) error {
+	address, err := p.address(position, mode)
+	if err != nil {
+		return err
+	}
+
+	if address < 0 {
+		return fmt.Errorf("Index %d out of range", address)
+	}
+
+	p.memory[address] = value
+
+	return nil
+}
+
+func (p *SymbolicProcess) Step() error {
+	operation, err := p.concrete(p.position, "opcode")
+	if err != nil {
+		return err
+	}
+
+	instruction := operation % 100
+	modes := []int{ParamMode(operation, 1), ParamMode(operation, 2), ParamMode(operation, 3)}
+
+	params := func(n int) ([]*Expr, error) {
+		result := []*Expr{}
+
+		for i := 0; i < n; i++ {
+			v, err := p.LoadParam(p.position+1+i, modes[i])
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, v)
+		}
+
+		return result, nil
+	}
+
+	switch instruction {
+	case OpcodeAdd, OpcodeMultiply, OpcodeLessThan, OpcodeEquals:
+		values, err := params(2)
+		if err != nil {
+			return err
+		}
+
+		op := map[int]string{
+			OpcodeAdd:      ExprAdd,
+			OpcodeMultiply: ExprMultiply,
+			OpcodeLessThan: ExprLessThan,
+			OpcodeEquals:   ExprEquals,
+		}[instruction]
+
+		if err := p.Write(p.position+3, Binary(op, values[0], values[1]), modes[2]); err != nil {
+			return err
+		}
+
+		p.position += 4
+	case OpcodeGetInput:
+		if p.inputPointer == len(p.inputs) {
+			return fmt.Errorf("Program needs more than %d inputs", len(p.inputs))
+		}
+
+		if err := p.Write(p.position+1, p.inputs[p.inputPointer], modes[0]); err != nil {
+			return err
+		}
+
+		p.inputPointer++
+		p.position += 2
+	case OpcodeWriteOutput:
+		values, err := params(1)
+		if err != nil {
+			return err
+		}
+
+		p.output = append(p.output, values[0])
+		p.position += 2
+	case OpcodeJumpIfTrue, OpcodeJumpIfFalse:
+		values, err := params(2)
+		if err != nil {
+			return err
+		}
+
+		if !values[0].IsConst() {
+			return &InputDependentError{Position: p.position, Reason: "jump condition", Expr: values[0]}
+		}
+
+		if !values[1].IsConst() {
+			return &InputDependentError{Position: p.position, Reason: "jump target", Expr: values[1]}
+		}
+
+		taken := values[0].value != 0
+		if instruction == OpcodeJumpIfFalse {
+			taken = !taken
+		}
+
+		if taken {
+			p.position = values[1].value
+		} else {
+			p.position += 3
+		}
+	case OpcodeAdjustRelativeBase:
+		values, err := params(1)
+		if err != nil {
+			return err
+		}
+
+		if !values[0].IsConst() {
+			return &InputDependentError{Position: p.position, Reason: "relative base", Expr: values[0]}
+		}
+
+		p.relativeBase += values[0].value
+		p.position += 2
+	case OpcodeHalt:
+		p.halted = true
+	default:
+		return fmt.Errorf("Unknonwn opcode %d", operation)
+	}
+
+	return nil
+}
+
+func (p *SymbolicProcess) Run() (*SymbolicResult, error) {
+	steps := 0
+
+	for !p.halted {
+		if steps >= p.maxSteps {
+			return nil, fmt.Errorf("Program did not halt after %d steps", steps)
+		}
+
+		if err := p.Step(); err != nil {
+			return nil, err
+		}
+
+		steps++
+	}
+
+	result := &SymbolicResult{
+		Outputs: p.output,
+		Memory:  map[int]*Expr{},
+		Steps:   steps,
+	}
+
+	for position, value := range p.memory {
+		if !value.IsConst() || position >= len(p.code) || p.code[position] != value.value {
+			result.Memory[position] = value
+		}
+	}
+
+	return result, nil
+}
+
+// Report lists the outputs and the memory cells that changed during the run.
+// Constant cells are skipped unless all is set.
+func (r *SymbolicResult) Report(all bool) string {
+	lines := []string{fmt.Sprintf("halted after %d steps", r.Steps)}
+
+	for i, out := range r.Outputs {
+		lines = append(lines, fmt.Sprintf("out[%d] = %s", i, out))
+	}
+
+	positions := []int{}
+	for position := range r.Memory {
+		positions = append(positions, position)
+	}
+	sort.Ints(positions)
+
+	for _, position := range positions {
+		value := r.Memory[position]
+
+		if value.IsConst() && !all {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("mem[%d] = %s", position, value))
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//
+// Remote intcode machines
+//
+// A Server hosts one Process behind a line based text protocol. Every request
+// is a single line, every response is a single line starting with OK or ERR:
+//
+//   RUN              run until interupt    OK <halted> <input pending> <unread outputs>
+//   RUNALL           run until halt        OK <halted> <input pending> <unread outputs>
+//   INPUT v1 v2 ...  queue input values    OK
+//   OUTPUT           read unread outputs   OK v1 v2 ...
+//   STATUS           query the state       OK <halted> <input pending> <unread outputs>
+//   SNAPSHOT         dump registers        OK <position> <relative base> <halted> m0 m1 ...
+//   QUIT             close the connection  OK
+//
+// A Client speaks the protocol and can be used everywhere a Process is used
+// as a Machine.
+//
+
+type Machine interface {
+	AddInput(val int)
+	RunTilInterupt() error
+	Run() error
+	NextOutput() int
+	HasOutput() bool
+	InputPending() bool
+	Halted() bool
+}
+
+func (p *Process) HasOutput() bool {
+	return len(p.output) > p.outputPointer
+}
+
+func (p *Process) InputPending() bool {
+	return len(p.input) > p.inputPointer
+}
+
+func (p *Process) Halted() bool {
+	return p.halted
+}
+
+type Snapshot struct {
+	Position     int
+	RelativeBase int
+	Halted       bool
+	Memory       []int
+}
+
+// Snapshot copies the registers and the used part of memory (trailing zeros
+// are dropped).
+func (p *Process) Snapshot() Snapshot {
+	end := len(p.memory)
+	for end > 0 && p.memory[end-1] == 0 {
+		end--
+	}
+
+	memory := make([]int, end)
+	copy(memory, p.memory[:end])
+
+	return Snapshot{
+		Position:     p.position,
+		RelativeBase: p.relativeBase,
+		Halted:       p.halted,
+		Memory:       memory,
+	}
+}
+
+//
+// Server
+//
+
+type Server struct {
+	process  *Process
+	listener net.Listener
+	mutex    sync.Mutex
+}
+
+// Listen starts serving the process on a "tcp" or "unix" address.
+func Listen(network, address string, p *Process) (*Server, error) {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{process: p, listener: listener}
+
+	go s.serve()
+
+	return s, nil
+}
+
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return // listener closed
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		response, quit := s.execute(strings.Fields(line))
+
+		writer.WriteString(response + "\n")
+		writer.Flush()
+
+		if quit {
+			return
+		}
+	}
+}
+
+func (s *Server) status() string {
+	p := s.process
+
+	return fmt.Sprintf("OK %d %d %d", boolToInt(p.halted), len(p.input)-p.inputPointer, len(p.output)-p.outputPointer)
+}
+
+func (s *Server) execute(command []string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.process
+
+	if len(command) == 0 {
+		return "ERR empty command", false
+	}
+
+	switch command[0] {
+	case "RUN":
+		if err := p.RunTilInterupt(); err != nil {
+			return "ERR " + err.Error(), false
+		}
+
+		return s.status(), false
+	case "RUNALL":
+		if err := p.Run(); err != nil {
+			return "ERR " + err.Error(), false
+		}
+
+		return s.status(), false
+	case "INPUT":
+		values, err := parseInts(command[1:])
+		if err != nil {
+			return "ERR " + err.Error(), false
+		}
+
+		for _, v := range values {
+			p.AddInput(v)
+		}
+
+		return "OK", false
+	case "OUTPUT":
+		values := []int{}
+
+		for p.HasOutput() {
+			values = append(values, p.NextOutput())
+		}
+
+		return strings.TrimSpace("OK " + joinInts(values)), false
+	case "STATUS":
+		return s.status(), false
+	case "SNAPSHOT":
+		snapshot := p.Snapshot()
+
+		return fmt.Sprintf("OK %d %d %d %s", snapshot.Position, snapshot.RelativeBase, boolToInt(snapshot.Halted), joinInts(snapshot.Memory)), false
+	case "QUIT":
+		return "OK", true
+	default:
+		return fmt.Sprintf("ERR unknown command %s", command[0]), false
+	}
+}
+
+//
+// Client
+//
+
+type Client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	output        []int
+	outputPointer int
+
+	halted  bool
+	pending int
+	unread  int
+
+	// the first failure is kept and returned by the next call that can
+	// report an error
+	err error
+}
+
+func Dial(network, address string) (*Client, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{conn: conn, reader: bufio.NewReader(conn)}
+
+	if err := c.requestStatus("STATUS"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Client) Close() error {
+	c.request("QUIT")
+
+	return c.conn.Close()
+}
+
+func (c *Client) Err() error {
+	return c.err
+}
+
+func (c *Client) request(command string) ([]string, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if _, err := fmt.Fprintf(c.conn, "%s\n", command); err != nil {
+		c.err = err
+		return nil, err
+	}
+
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		c.err = err
+		return nil, err
+	}
+
+	fields := strings.Fields(line)
+
+	if len(fields) == 0 || fields[0] != "OK" {
+		c.err = fmt.Errorf("Remote error: %s", strings.TrimSpace(strings.TrimPrefix(line, "ERR")))
+		return nil, c.err
+	}
+
+	return fields[1:], nil
+}
+
+func (c *Client) requestStatus(command string) error {
+	fields, err := c.request(command)
+	if err != nil {
+		return err
+	}
+
+	values, err := parseInts(fields)
+	if err != nil || len(values) != 3 {
+		c.err = fmt.Errorf("Malformed status %v", fields)
+		return c.err
+	}
+
+	c.halted = values[0] == 1
+	c.pending = values[1]
+	c.unread = values[2]
+
+	return nil
+}
+
+func (c *Client) AddInput(val int) {
+	if _, err := c.request(fmt.Sprintf("INPUT %d", val)); err == nil {
+		c.pending++
+	}
+}
+
+func (c *Client) RunTilInterupt() error {
+	return c.requestStatus("RUN")
+}
+
+func (c *Client) Run() error {
+	return c.requestStatus("RUNALL")
+}
+
+func (c *Client) fetchOutput() {
+	if c.unread == 0 {
+		return
+	}
+
+	fields, err := c.request("OUTPUT")
+	if err != nil {
+		return
+	}
+
+	values, err := parseInts(fields)
+	if err != nil {
+		c.err = err
+		return
+	}
+
+	c.output = append(c.output, values...)
+	c.unread = 0
+}
+
+func (c *Client) HasOutput() bool {
+	c.fetchOutput()
+
+	return len(c.output) > c.outputPointer
+}
+
+// NextOutput panics like Process.NextOutput when there is no output left.
+func (c *Client) NextOutput() int {
+	c.fetchOutput()
+
+	res := c.output[c.outputPointer]
+	c.outputPointer++
+	return res
+}
+
+func (c *Client) InputPending() bool {
+	return c.pending > 0
+}
+
+func (c *Client) Halted() bool {
+	return c.halted
+}
+
+func (c *Client) Snapshot() (Snapshot, error) {
+	fields, err := c.request("SNAPSHOT")
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	values, err := parseInts(fields)
+	if err != nil || len(values) < 3 {
+		return Snapshot{}, fmt.Errorf("Malformed snapshot")
+	}
+
+	return Snapshot{
+		Position:     values[0],
+		RelativeBase: values[1],
+		Halted:       values[2] == 1,
+		Memory:       values[3:],
+	}, nil
+}
+
+//
+// helpers
+//
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func parseInts(fields []string) ([]int, error) {
+	result := []int{}
+
+	for _, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number %s", f)
+		}
+
+		result = append(result, v)
+	}
+
+	return result, nil
+}
+
+func joinInts(values []int) string {
+	parts := []string{}
+
+	for _, v := range values {
+		parts = append(parts, strconv.Itoa(v))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,322 @@
+// Package flood simulates something spreading across a grid, one neighbour
+// at a time, and records when every cell was reached.
+package flood
+
+import (
+	"container/heap"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+type Point struct {
+	X, Y int
+}
+
+var directions = []Point{
+	Point{X: 0, Y: -1},
+	Point{X: 0, Y: 1},
+	Point{X: -1, Y: 0},
+	Point{X: 1, Y: 0},
+}
+
+// Neighbours4 returns the cells above, below, left and right of p.
+func Neighbours4(p Point) []Point {
+	result := []Point{}
+
+	for _, d := range directions {
+		result = append(result, Point{X: p.X + d.X, Y: p.Y + d.Y})
+	}
+
+	return result
+}
+
+// Flood describes a spread, only Sources is required. An open grid without
+// Wall, Blocked or Limit never stops spreading.
+type Flood struct {
+	Sources []Point
+
+	// Cells reachable from a cell, Neighbours4 by default.
+	Neighbours func(p Point) []Point
+
+	// Reports cells that can never be entered, like walls and the edge of
+	// the grid. They are rejected once and never tried again.
+	Wall func(p Point) bool
+
+	// Reports cells that can't be entered at the given time, for obstacles
+	// that move. A blocked cell is tried again every time step, until Limit
+	// or, without a Limit, until the flood stops reaching new cells, so
+	// static walls belong in Wall. Sources are checked at time 0 and wait
+	// the same way.
+	Blocked func(p Point, time int) bool
+
+	// Time needed to enter a cell, 1 by default.
+	Delay func(p Point) int
+
+	// Stop spreading after this time, 0 means no limit.
+	Limit int
+}
+
+type Result struct {
+	Arrival map[Point]int
+	Time    int
+}
+
+func (f *Flood) Run() (*Result, error) {
+	if len(f.Sources) == 0 {
+		return nil, fmt.Errorf("Flood has no sources")
+	}
+
+	neighbours := f.Neighbours
+	if neighbours == nil {
+		neighbours = Neighbours4
+	}
+
+	result := &Result{Arrival: map[Point]int{}}
+	queue := &arrivals{}
+
+	// cells waiting for a blocked neighbour to open, the number of queued
+	// arrivals that are not such retries and the last time a cell was reached
+	waiting := map[Point]bool{}
+	pending := 0
+	last := 0
+
+	retry := func(p Point, time int) {
+		if f.Limit > 0 && time+1 > f.Limit {
+			delete(waiting, p)
+			return
+		}
+
+		waiting[p] = true
+		heap.Push(queue, arrival{pos: p, time: time + 1, retry: true})
+	}
+
+	for _, s := range f.Sources {
+		if f.Wall != nil && f.Wall(s) {
+			return nil, fmt.Errorf("Source %v is inside a wall", s)
+		}
+
+		if f.Blocked != nil && f.Blocked(s, 0) {
+			if !waiting[s] {
+				retry(s, 0)
+			}
+
+			continue
+		}
+
+		heap.Push(queue, arrival{pos: s, time: 0})
+		pending++
+	}
+
+	for queue.Len() > 0 {
+		a := heap.Pop(queue).(arrival)
+
+		if !a.retry {
+			pending--
+		}
+
+		if _, done := result.Arrival[a.pos]; done {
+			if a.retry {
+				delete(waiting, a.pos)
+			}
+
+			continue
+		}
+
+		if a.retry && f.Blocked(a.pos, a.time) {
+			// keep waiting while the flood still spreads, a stalled flood
+			// gives up on cells that stay blocked
+			if f.Limit > 0 || pending > 0 || last >= a.time-1 {
+				retry(a.pos, a.time)
+			} else {
+				delete(waiting, a.pos)
+			}
+
+			continue
+		}
+
+		result.Arrival[a.pos] = a.time
+		last = a.time
+
+		if a.time > result.Time {
+			result.Time = a.time
+		}
+
+		for _, n := range neighbours(a.pos) {
+			if _, done := result.Arrival[n]; done {
+				continue
+			}
+
+			if f.Wall != nil && f.Wall(n) {
+				continue
+			}
+
+			delay := 1
+			if f.Delay != nil {
+				delay = f.Delay(n)
+			}
+
+			if delay < 1 {
+				return nil, fmt.Errorf("Invalid delay %d at %v", delay, n)
+			}
+
+			time := a.time + delay
+
+			if f.Limit > 0 && time > f.Limit {
+				continue
+			}
+
+			if f.Blocked != nil && f.Blocked(n, time) {
+				if !waiting[n] {
+					retry(n, time)
+				}
+
+				continue
+			}
+
+			heap.Push(queue, arrival{pos: n, time: time})
+			pending++
+		}
+	}
+
+	return result, nil
+}
+
+// Reached lists the cells reached at or before the given time.
+func (r *Result) Reached(time int) []Point {
+	result := []Point{}
+
+	for p, t := range r.Arrival {
+		if t <= time {
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
+
+func (r *Result) Bounds() (Point, Point) {
+	first := true
+	min, max := Point{}, Point{}
+
+	for p := range r.Arrival {
+		if first {
+			min, max = p, p
+			first = false
+		}
+
+		if p.X < min.X {
+			min.X = p.X
+		}
+		if p.Y < min.Y {
+			min.Y = p.Y
+		}
+		if p.X > max.X {
+			max.X = p.X
+		}
+		if p.Y > max.Y {
+			max.Y = p.Y
+		}
+	}
+
+	return min, max
+}
+
+const ramp = "0123456789"
+
+// HeatMap draws arrival times as digits from 0 (first) to 9 (last), cells
+// that were never reached are blank.
+func (r *Result) HeatMap() string {
+	min, max := r.Bounds()
+	lines := []string{}
+
+	for y := min.Y; y <= max.Y; y++ {
+		line := ""
+
+		for x := min.X; x <= max.X; x++ {
+			t, ok := r.Arrival[Point{X: x, Y: y}]
+			if !ok {
+				line += " "
+				continue
+			}
+
+			line += string(ramp[r.level(t, len(ramp))])
+		}
+
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// WritePNG draws the heat map, from blue (first) to red (last), with
+// unreached cells in black.
+func (r *Result) WritePNG(w io.Writer, scale int) error {
+	if scale < 1 {
+		return fmt.Errorf("Invalid scale %d", scale)
+	}
+
+	min, max := r.Bounds()
+	width := (max.X - min.X + 1) * scale
+	height := (max.Y - min.Y + 1) * scale
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for p, t := range r.Arrival {
+		level := uint8(r.level(t, 256))
+		c := color.RGBA{R: level, G: 0x40, B: 0xff - level, A: 0xff}
+
+		for dy := 0; dy < scale; dy++ {
+			for dx := 0; dx < scale; dx++ {
+				img.Set((p.X-min.X)*scale+dx, (p.Y-min.Y)*scale+dy, c)
+			}
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if img.RGBAAt(x, y).A == 0 {
+				img.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// level scales a time into 0..levels-1
+func (r *Result) level(time, levels int) int {
+	if r.Time == 0 {
+		return 0
+	}
+
+	return time * (levels - 1) / r.Time
+}
+
+//
+// Priority queue of arrivals, earliest first
+//
+
+type arrival struct {
+	pos   Point
+	time  int
+	retry bool
+}
+
+type arrivals []arrival
+
+func (a arrivals) Len() int            { return len(a) }
+func (a arrivals) Less(i, j int) bool  { return a[i].time < a[j].time }
+func (a arrivals) Swap(i, j int)       { a[i], a[j] = a[j], a[i] }
+func (a *arrivals) Push(x interface{}) { *a = append(*a, x.(arrival)) }
+
+func (a *arrivals) Pop() interface{} {
+	old := *a
+	last := old[len(old)-1]
+	*a = old[:len(old)-1]
+
+	return last
+}
@@ -0,0 +1,158 @@
+// Package ocr reads capital letters drawn in the 4x6 font used by the puzzles.
+package ocr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capital letters of the 4x6 puzzle font, '#' is a lit pixel. Glyphs are
+// compared after trimming empty columns, so narrower letters work too.
+var glyphs = map[string]string{
+	".##.\n#..#\n#..#\n####\n#..#\n#..#":       "A",
+	"###.\n#..#\n###.\n#..#\n#..#\n###.":       "B",
+	".##.\n#..#\n#...\n#...\n#..#\n.##.":       "C",
+	"####\n#...\n###.\n#...\n#...\n####":       "E",
+	"####\n#...\n###.\n#...\n#...\n#...":       "F",
+	".##.\n#..#\n#...\n#.##\n#..#\n.###":       "G",
+	"#..#\n#..#\n####\n#..#\n#..#\n#..#":       "H",
+	"###\n.#.\n.#.\n.#.\n.#.\n###":             "I",
+	"..##\n...#\n...#\n...#\n#..#\n.##.":       "J",
+	"#..#\n#.#.\n##..\n#.#.\n#.#.\n#..#":       "K",
+	"#...\n#...\n#...\n#...\n#...\n####":       "L",
+	".##.\n#..#\n#..#\n#..#\n#..#\n.##.":       "O",
+	"###.\n#..#\n#..#\n###.\n#...\n#...":       "P",
+	"###.\n#..#\n#..#\n###.\n#.#.\n#..#":       "R",
+	".###\n#...\n#...\n.##.\n...#\n###.":       "S",
+	"#..#\n#..#\n#..#\n#..#\n#..#\n.##.":       "U",
+	"#...#\n#...#\n.#.#.\n..#..\n..#..\n..#..": "Y",
+	"####\n...#\n..#.\n.#..\n#...\n####":       "Z",
+}
+
+// Recognize splits the grid into glyphs at empty columns and reads them.
+// Empty rows above and below the text are ignored. Unknown glyphs are
+// reported with their bitmap, ready to be added to the table.
+func Recognize(grid [][]bool) (string, error) {
+	result := ""
+	unknown := []string{}
+
+	for _, glyph := range splitGlyphs(trimRows(grid)) {
+		if letter, ok := glyphs[glyph]; ok {
+			result += letter
+			continue
+		}
+
+		result += "?"
+		unknown = append(unknown, glyph)
+	}
+
+	if len(unknown) > 0 {
+		return result, fmt.Errorf("Unknown glyphs:\n%s", strings.Join(unknown, "\n\n"))
+	}
+
+	return result, nil
+}
+
+// Parse turns text art into a grid, every lit rune is a set pixel. Short
+// lines are padded so the grid is rectangular.
+func Parse(text string, lit rune) [][]bool {
+	grid := [][]bool{}
+	width := 0
+
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		row := []bool{}
+
+		for _, r := range line {
+			row = append(row, r == lit)
+		}
+
+		if len(row) > width {
+			width = len(row)
+		}
+
+		grid = append(grid, row)
+	}
+
+	for i := range grid {
+		for len(grid[i]) < width {
+			grid[i] = append(grid[i], false)
+		}
+	}
+
+	return grid
+}
+
+func trimRows(grid [][]bool) [][]bool {
+	empty := func(row []bool) bool {
+		for _, set := range row {
+			if set {
+				return false
+			}
+		}
+		return true
+	}
+
+	for len(grid) > 0 && empty(grid[0]) {
+		grid = grid[1:]
+	}
+
+	for len(grid) > 0 && empty(grid[len(grid)-1]) {
+		grid = grid[:len(grid)-1]
+	}
+
+	return grid
+}
+
+func splitGlyphs(grid [][]bool) []string {
+	if len(grid) == 0 {
+		return nil
+	}
+
+	empty := func(x int) bool {
+		for _, row := range grid {
+			if row[x] {
+				return false
+			}
+		}
+		return true
+	}
+
+	result := []string{}
+	from := -1
+
+	for x := 0; x <= len(grid[0]); x++ {
+		if x < len(grid[0]) && !empty(x) {
+			if from == -1 {
+				from = x
+			}
+			continue
+		}
+
+		if from != -1 {
+			result = append(result, bitmap(grid, from, x))
+			from = -1
+		}
+	}
+
+	return result
+}
+
+func bitmap(grid [][]bool, from, to int) string {
+	lines := []string{}
+
+	for _, row := range grid {
+		line := ""
+
+		for x := from; x < to; x++ {
+			if row[x] {
+				line += "#"
+			} else {
+				line += "."
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
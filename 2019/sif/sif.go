@@ -0,0 +1,188 @@
+// Package sif reads and writes images in the Space Image Format, a stream of
+// digits split into layers of width x height pixels.
+package sif
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	Black       = 0
+	White       = 1
+	Transparent = 2
+)
+
+type Layer struct {
+	Width  int
+	Height int
+	Pixels []int
+}
+
+type Image struct {
+	Width  int
+	Height int
+	Layers []*Layer
+}
+
+func NewLayer(width, height int) *Layer {
+	return &Layer{Width: width, Height: height, Pixels: make([]int, width*height)}
+}
+
+func (l *Layer) At(x, y int) int {
+	return l.Pixels[y*l.Width+x]
+}
+
+func (l *Layer) Set(x, y, pixel int) {
+	l.Pixels[y*l.Width+x] = pixel
+}
+
+func (l *Layer) Count(digit int) int {
+	result := 0
+
+	for _, p := range l.Pixels {
+		if p == digit {
+			result++
+		}
+	}
+
+	return result
+}
+
+// Grid marks the white pixels row by row.
+func (l *Layer) Grid() [][]bool {
+	grid := [][]bool{}
+
+	for y := 0; y < l.Height; y++ {
+		row := []bool{}
+
+		for x := 0; x < l.Width; x++ {
+			row = append(row, l.At(x, y) == White)
+		}
+
+		grid = append(grid, row)
+	}
+
+	return grid
+}
+
+// Decode reads the digit stream. Trailing whitespace is ignored, the rest
+// must be digits and fill a whole number of layers.
+func Decode(r io.Reader, width, height int) (*Image, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("Invalid dimensions %dx%d", width, height)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	digits := strings.TrimRight(string(data), " \t\r\n")
+	size := width * height
+
+	if len(digits) == 0 {
+		return nil, fmt.Errorf("Image is empty")
+	}
+
+	if len(digits)%size != 0 {
+		return nil, fmt.Errorf("Image has %d digits, not a multiple of %dx%d=%d", len(digits), width, height, size)
+	}
+
+	img := &Image{Width: width, Height: height}
+
+	for i := 0; i < len(digits); i += size {
+		layer := NewLayer(width, height)
+
+		for j := 0; j < size; j++ {
+			c := digits[i+j]
+
+			if c < '0' || c > '9' {
+				return nil, fmt.Errorf("Invalid digit %q at offset %d", c, i+j)
+			}
+
+			layer.Pixels[j] = int(c - '0')
+		}
+
+		img.Layers = append(img.Layers, layer)
+	}
+
+	return img, nil
+}
+
+// Encode writes the image back as a digit stream, without a trailing newline.
+func (img *Image) Encode(w io.Writer) error {
+	var b strings.Builder
+
+	for _, l := range img.Layers {
+		for _, p := range l.Pixels {
+			if p < 0 || p > 9 {
+				return fmt.Errorf("Pixel %d is not a digit", p)
+			}
+
+			b.WriteByte(byte('0' + p))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// MergeLayers stacks the layers, the first non transparent pixel from the
+// top wins.
+func (img *Image) MergeLayers() *Layer {
+	result := NewLayer(img.Width, img.Height)
+
+	for i := range result.Pixels {
+		result.Pixels[i] = Transparent
+	}
+
+	for _, l := range img.Layers {
+		for i, p := range l.Pixels {
+			if result.Pixels[i] == Transparent {
+				result.Pixels[i] = p
+			}
+		}
+	}
+
+	return result
+}
+
+var colors = map[int]color.Color{
+	Black:       color.NRGBA{0x00, 0x00, 0x00, 0xff},
+	White:       color.NRGBA{0xff, 0xff, 0xff, 0xff},
+	Transparent: color.NRGBA{0x00, 0x00, 0x00, 0x00},
+}
+
+// WritePNG draws every pixel as a scale x scale square. Transparent pixels
+// stay transparent.
+func (l *Layer) WritePNG(w io.Writer, scale int) error {
+	if scale < 1 {
+		return fmt.Errorf("Invalid scale %d", scale)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, l.Width*scale, l.Height*scale))
+
+	for y := 0; y < l.Height; y++ {
+		for x := 0; x < l.Width; x++ {
+			c, ok := colors[l.At(x, y)]
+			if !ok {
+				return fmt.Errorf("Unknown color %d at %d,%d", l.At(x, y), x, y)
+			}
+
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.Set(x*scale+dx, y*scale+dy, c)
+				}
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}